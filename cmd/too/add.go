@@ -69,7 +69,7 @@ var addCmd = &cobra.Command{
 
 			// Check if user provided any content
 			if editedText == "" {
-				return fmt.Errorf("no content provided")
+				return too.NewValidationError("no content provided")
 			}
 
 			text = editedText
@@ -98,7 +98,7 @@ var addCmd = &cobra.Command{
 			// Parse multiple todos
 			todos := parser.ParseMultipleTodos(text, parser.DefaultParseOptions())
 			if len(todos) == 0 {
-				return fmt.Errorf("no todos found in input")
+				return too.NewValidationError("no todos found in input")
 			}
 
 			// For now, we'll add them one by one
@@ -161,7 +161,7 @@ var addCmd = &cobra.Command{
 			"collectionPath": collectionPath,
 			"parent":         parentPath,
 		}
-		result, err := too.ExecuteUnifiedCommand("add", []string{text}, opts)
+		result, err := executeInteractive("add", []string{text}, opts)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/arthur-debert/too/pkg/too/commands/batch"
+	"github.com/arthur-debert/too/pkg/too/commands/datapath"
+	"github.com/spf13/cobra"
+)
+
+var batchCmd = &cobra.Command{
+	Use:     msgBatchUse,
+	Short:   msgBatchShort,
+	Long:    msgBatchLong,
+	GroupID: "misc",
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get collection path from flag
+		collectionPath := resolveDataPath(cmd)
+
+		// Ensure gitignore is updated for project scope
+		if err := datapath.EnsureProjectGitignore(); err != nil {
+			// Log but don't fail
+			fmt.Printf("Warning: could not update .gitignore: %v\n", err)
+		}
+
+		// Read from the given file or stdin
+		var input io.Reader = os.Stdin
+		if len(args) > 0 {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open batch file: %w", err)
+			}
+			defer file.Close()
+			input = file
+		}
+
+		// Call business logic
+		result, err := batch.Execute(batch.Options{
+			CollectionPath: collectionPath,
+			Input:          input,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Render output
+		return renderToStdout(result)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+}
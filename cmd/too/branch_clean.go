@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/spf13/cobra"
+)
+
+var branchCleanCmd = &cobra.Command{
+	Use:     msgBranchCleanUse,
+	Short:   msgBranchCleanShort,
+	Long:    msgBranchCleanLong,
+	GroupID: "misc",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Call business logic
+		result, err := too.CleanBranches(map[string]interface{}{
+			"collectionPath": resolveDataPath(cmd),
+		})
+		if err != nil {
+			return err
+		}
+
+		// Render output
+		return renderToStdout(result)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(branchCleanCmd)
+}
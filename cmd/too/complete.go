@@ -4,7 +4,6 @@ import (
 	"fmt"
 	
 	"github.com/arthur-debert/too/pkg/too/commands/datapath"
-	"github.com/arthur-debert/too/pkg/too"
 	"github.com/spf13/cobra"
 )
 
@@ -14,7 +13,7 @@ var completeCmd = &cobra.Command{
 	Short:   msgCompleteShort,
 	Long:    msgCompleteLong,
 	GroupID: "core",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    pickableArgs(cobra.MinimumNArgs(1)),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get collection path from flag
 		collectionPath := resolveDataPath(cmd)
@@ -29,7 +28,14 @@ var completeCmd = &cobra.Command{
 		opts := map[string]interface{}{
 			"collectionPath": collectionPath,
 		}
-		result, err := too.ExecuteUnifiedCommand("complete", args, opts)
+		if len(args) == 0 {
+			todo, err := pickTodo(msgPickComplete, opts, "", false)
+			if err != nil {
+				return err
+			}
+			args = []string{todo.UID}
+		}
+		result, err := executeInteractive("complete", args, opts)
 		if err != nil {
 			return err
 		}
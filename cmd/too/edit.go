@@ -18,10 +18,10 @@ var editCmd = &cobra.Command{
 	Short:   msgEditShort,
 	Long:    msgEditLong,
 	GroupID: "core",
-	Args: func(cmd *cobra.Command, args []string) error {
+	Args: pickableArgs(func(cmd *cobra.Command, args []string) error {
 		// Need at least position argument
 		if len(args) < 1 {
-			return fmt.Errorf("position argument is required")
+			return too.NewValidationError("position argument is required")
 		}
 		// If using editor, we only need position
 		if editUseEditor {
@@ -29,14 +29,11 @@ var editCmd = &cobra.Command{
 		}
 		// Otherwise, we need position and text
 		if len(args) < 2 {
-			return fmt.Errorf("both position and text arguments are required")
+			return too.NewValidationError("both position and text arguments are required")
 		}
 		return nil
-	},
+	}),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// The position is the first argument
-		position := args[0]
-
 		var text string
 		collectionPath := resolveDataPath(cmd)
 		
@@ -46,6 +43,27 @@ var editCmd = &cobra.Command{
 			fmt.Printf("Warning: could not update .gitignore: %v\n", err)
 		}
 
+		// Without a position, pick the todo and start from its current text
+		if len(args) == 0 {
+			todo, err := pickTodo(msgPickEdit, map[string]interface{}{"collectionPath": collectionPath}, "", false)
+			if err != nil {
+				return err
+			}
+			args = []string{todo.UID}
+			if editUseEditor {
+				args = append(args, todo.Text)
+			} else {
+				newText, err := stdinPicker().ask(fmt.Sprintf(msgPickEditText, todo.Text))
+				if err != nil {
+					return err
+				}
+				args = append(args, newText)
+			}
+		}
+
+		// The position is the first argument
+		position := args[0]
+
 		// Handle editor mode
 		if editUseEditor {
 			// Get current todo to pre-populate editor
@@ -63,7 +81,7 @@ var editCmd = &cobra.Command{
 
 			// Check if user provided any content
 			if editedText == "" {
-				return fmt.Errorf("no content provided")
+				return too.NewValidationError("no content provided")
 			}
 
 			text = editedText
@@ -76,7 +94,7 @@ var editCmd = &cobra.Command{
 		opts := map[string]interface{}{
 			"collectionPath": collectionPath,
 		}
-		result, err := too.ExecuteUnifiedCommand("edit", []string{position, text}, opts)
+		result, err := executeInteractive("edit", []string{position, text}, opts)
 		if err != nil {
 			return err
 		}
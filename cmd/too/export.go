@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:     msgExportUse,
+	Short:   msgExportShort,
+	Long:    msgExportLong,
+	GroupID: "misc",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get collection path from flag
+		collectionPath := resolveDataPath(cmd)
+
+		// Exports always include done todos
+		opts := map[string]interface{}{
+			"collectionPath": collectionPath,
+			"all":            true,
+		}
+		result, err := too.ExecuteUnifiedCommand("list", []string{}, opts)
+		if err != nil {
+			return err
+		}
+
+		// Terminal output is not an interchange format, default to json
+		format := formatFlag
+		if !cmd.Flags().Changed("format") {
+			format = "json"
+		}
+
+		return render(os.Stdout, format, result)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+}
@@ -1,13 +1,54 @@
 package main
 
 import (
+	"os"
+
 	"github.com/arthur-debert/too/pkg/too/commands/datapath"
 	"github.com/spf13/cobra"
 )
 
+// projectStore is the project store found from the current directory, with
+// its file as it was before the command ran (nil when there was none)
+var projectStore struct {
+	path   string
+	before os.FileInfo
+}
+
 // resolveDataPath resolves the data path from command flags
 func resolveDataPath(cmd *cobra.Command) string {
 	rawPath, _ := cmd.Flags().GetString("data-path")
 	isGlobal, _ := cmd.Flags().GetBool("global")
-	return datapath.ResolveCollectionPathWithGlobal(rawPath, isGlobal)
-}
\ No newline at end of file
+	path := datapath.ResolveCollectionPathWithGlobal(rawPath, isGlobal)
+
+	if rawPath == "" && !isGlobal && os.Getenv("TODO_DB_PATH") == "" {
+		watchProjectStore(path)
+	}
+	return path
+}
+
+// watchProjectStore notes the state of the project store at path, so that
+// recordProject can tell whether the command wrote it
+func watchProjectStore(path string) {
+	if projectStore.path != "" {
+		return
+	}
+	projectStore.path = path
+	projectStore.before, _ = os.Stat(path)
+}
+
+// recordProject remembers the project store for listings across projects
+// once a command created or changed it. That's a convenience, so errors are
+// ignored.
+func recordProject() {
+	if projectStore.path == "" {
+		return
+	}
+	after, err := os.Stat(projectStore.path)
+	if err != nil {
+		return
+	}
+	if before := projectStore.before; before != nil && before.ModTime().Equal(after.ModTime()) && before.Size() == after.Size() {
+		return
+	}
+	_ = datapath.RecordProject()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/arthur-debert/too/pkg/too/commands/datapath"
+	"github.com/arthur-debert/too/pkg/too/commands/importer"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:       msgImportUse,
+	Short:     msgImportShort,
+	Long:      msgImportLong,
+	GroupID:   "misc",
+	Args:      cobra.RangeArgs(1, 2),
+	ValidArgs: importer.Formats(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := args[0]
+
+		// Get collection path from flag
+		collectionPath := resolveDataPath(cmd)
+
+		// Ensure gitignore is updated for project scope
+		if err := datapath.EnsureProjectGitignore(); err != nil {
+			// Log but don't fail
+			fmt.Printf("Warning: could not update .gitignore: %v\n", err)
+		}
+
+		// Read from the given file or stdin
+		var input io.Reader = os.Stdin
+		if len(args) > 1 {
+			file, err := os.Open(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to open import file: %w", err)
+			}
+			defer file.Close()
+			input = file
+		}
+
+		// Call business logic
+		result, err := importer.Execute(importer.Options{
+			CollectionPath: collectionPath,
+			Format:         format,
+			Input:          input,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Render output - just use the message field
+		return renderToStdout(result.Message)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
@@ -7,7 +7,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var initUseHomeDir bool
+var (
+	initUseHomeDir bool
+	initShared     bool
+	initMerge      bool
+)
 
 var initCmd = &cobra.Command{
 	Use:     msgInitUse,
@@ -38,7 +42,8 @@ var initCmd = &cobra.Command{
 		} else {
 			// Default to project scope or current directory
 			path, isGlobalScope := datapath.ResolveScopedPath(false)
-			if !isGlobalScope {
+			watchProjectStore(path)
+			if !isGlobalScope && !initShared {
 				// Ensure gitignore is updated for project scope
 				if err := datapath.EnsureProjectGitignore(); err != nil {
 					// Log but don't fail
@@ -50,8 +55,10 @@ var initCmd = &cobra.Command{
 
 		// Call business logic
 		result, err := cmdInit.Execute(cmdInit.Options{
-			DBPath:     collectionPath,
-			UseHomeDir: initUseHomeDir && explicitPath == "",
+			DBPath:             collectionPath,
+			UseHomeDir:         initUseHomeDir && explicitPath == "",
+			Shared:             initShared,
+			InstallMergeDriver: initMerge,
 		})
 		if err != nil {
 			return err
@@ -65,4 +72,6 @@ var initCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(initCmd)
 	initCmd.Flags().BoolVar(&initUseHomeDir, "home", false, "Create .todos file in home directory instead of current directory")
+	initCmd.Flags().BoolVar(&initShared, "shared", false, msgFlagShared)
+	initCmd.Flags().BoolVar(&initMerge, "install-merge-driver", false, msgFlagInstallMergeDriver)
 }
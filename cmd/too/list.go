@@ -2,12 +2,17 @@ package main
 
 import (
 	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/scope"
 	"github.com/spf13/cobra"
 )
 
 var (
 	showDone bool
 	showAll  bool
+
+	listEverywhere bool
+	listWithGlobal bool
+	listRecursive  bool
 )
 
 var listCmd = &cobra.Command{
@@ -26,6 +31,27 @@ var listCmd = &cobra.Command{
 			"done":           showDone,
 			"all":            showAll,
 		}
+		if listEverywhere {
+			result, err := too.ListEverywhere(scope.DefaultRegistryPath(), opts)
+			if err != nil {
+				return err
+			}
+			return renderToStdout(result)
+		}
+		if listRecursive {
+			result, err := too.ListRecursive(opts)
+			if err != nil {
+				return err
+			}
+			return renderToStdout(result)
+		}
+		if listWithGlobal || too.GetConfig().Display.ShowGlobalTodos {
+			result, err := too.ListWithGlobal(scope.DefaultRegistryPath(), opts)
+			if err != nil {
+				return err
+			}
+			return renderToStdout(result)
+		}
 		result, err := too.ExecuteUnifiedCommand("list", []string{}, opts)
 		if err != nil {
 			return err
@@ -40,6 +66,9 @@ func init() {
 	// Add flags for filtering
 	listCmd.Flags().BoolVarP(&showDone, "done", "d", false, msgFlagDone)
 	listCmd.Flags().BoolVarP(&showAll, "all", "a", false, msgFlagAll)
+	listCmd.Flags().BoolVarP(&listEverywhere, "everywhere", "e", false, msgFlagEverywhere)
+	listCmd.Flags().BoolVarP(&listWithGlobal, "with-global", "G", false, msgFlagWithGlobal)
+	listCmd.Flags().BoolVarP(&listRecursive, "recursive", "r", false, msgFlagRecursive)
 
 	rootCmd.AddCommand(listCmd)
 }
@@ -3,12 +3,17 @@ package main
 import (
 	"os"
 
+	"github.com/arthur-debert/too/pkg/too"
 	"github.com/rs/zerolog/log"
 )
 
 func main() {
 	if err := Execute(); err != nil {
-		log.Error().Err(err).Msg(msgCommandFailed)
-		os.Exit(1)
+		// Errors are rendered in the requested format so scripts using
+		// --format json can tell an ambiguous reference from a missing one
+		if renderErr := renderError(os.Stderr, formatFlag, err); renderErr != nil {
+			log.Error().Err(err).Msg(msgCommandFailed)
+		}
+		os.Exit(too.ExitCode(err))
 	}
 }
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arthur-debert/too/pkg/too/store"
+	"github.com/spf13/cobra"
+)
+
+var mergeDriverCmd = &cobra.Command{
+	Use:     msgMergeDriverUse,
+	Short:   msgMergeDriverShort,
+	Long:    msgMergeDriverLong,
+	GroupID: "misc",
+	Args:    cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		versions := make([][]byte, len(args))
+		for i, path := range args {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			versions[i] = content
+		}
+
+		merged, conflicts, err := store.MergeStores(versions[0], versions[1], versions[2])
+		if err != nil {
+			return err
+		}
+
+		// Git takes the result from ours; stdout is its terminal
+		if err := os.WriteFile(args[1], merged, 0644); err != nil {
+			return err
+		}
+		switch {
+		case conflicts == 1:
+			fmt.Fprintln(os.Stderr, "too: kept both versions of 1 todo changed on both sides")
+		case conflicts > 1:
+			fmt.Fprintf(os.Stderr, "too: kept both versions of %d todos changed on both sides\n", conflicts)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeDriverCmd)
+}
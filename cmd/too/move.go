@@ -3,10 +3,14 @@ package main
 import (
 	"fmt"
 	
+	"github.com/arthur-debert/too/pkg/too"
 	"github.com/arthur-debert/too/pkg/too/commands/datapath"
 	"github.com/spf13/cobra"
+)
 
-	"github.com/arthur-debert/too/pkg/too"
+var (
+	moveToScope string
+	moveCopy    bool
 )
 
 var moveCmd = &cobra.Command{
@@ -15,11 +19,16 @@ var moveCmd = &cobra.Command{
 	Short:   msgMoveShort,
 	Long:    msgMoveLong,
 	GroupID: "extras",
-	Args:    cobra.ExactArgs(2),
+	Args: pickableArgs(func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("to-scope") {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		if moveCopy {
+			return too.NewValidationError("--copy needs --to-scope: todos are copied to another store")
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	}),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		sourcePath := args[0]
-		destParentPath := args[1]
-
 		// Get collection path from command flags
 		collectionPath := resolveDataPath(cmd)
 		
@@ -33,7 +42,35 @@ var moveCmd = &cobra.Command{
 		opts := map[string]interface{}{
 			"collectionPath": collectionPath,
 		}
-		result, err := too.ExecuteUnifiedCommand("move", []string{sourcePath, destParentPath}, opts)
+		if cmd.Flags().Changed("to-scope") {
+			if len(args) == 0 {
+				source, err := pickTodo(msgPickMove, opts, "", false)
+				if err != nil {
+					return err
+				}
+				args = []string{source.UID}
+			}
+			result, err := too.TransferTodo(args[0], moveToScope, moveCopy, opts)
+			if err != nil {
+				return err
+			}
+			return renderToStdout(result)
+		}
+		if len(args) == 0 {
+			source, err := pickTodo(msgPickMove, opts, "", false)
+			if err != nil {
+				return err
+			}
+			destination, err := pickTodo(fmt.Sprintf(msgPickMoveTo, source.Text), opts, source.UID, true)
+			if err != nil {
+				return err
+			}
+			args = []string{source.UID, ""}
+			if destination != nil {
+				args[1] = destination.UID
+			}
+		}
+		result, err := executeInteractive("move", []string{args[0], args[1]}, opts)
 		if err != nil {
 			return err
 		}
@@ -44,5 +81,8 @@ var moveCmd = &cobra.Command{
 }
 
 func init() {
+	moveCmd.Flags().StringVar(&moveToScope, "to-scope", "", msgFlagToScope)
+	moveCmd.Flags().BoolVar(&moveCopy, "copy", false, msgFlagCopy)
+
 	rootCmd.AddCommand(moveCmd)
 }
@@ -13,7 +13,12 @@ const (
 Naked execution shortcuts:
   too                    # lists all todos (same as 'too list')
   too Buy milk          # adds "Buy milk" (same as 'too add "Buy milk"')
-  too --to 1 Eggs       # adds "Eggs" under todo #1`
+  too --to 1 Eggs       # adds "Eggs" under todo #1
+
+Todos can be referenced by position (1, 2.1, c1), short ID (@a3f9), text
+(milk) or relation: last (the latest added or changed), 2^ or parent:2,
+first:2 and last:2 (children), next:2 and prev:2 (siblings). Relations
+nest, so "too add --to last^ Jam" adds next to what was just added.`
 	msgRootVersion = "too version {{.Version}}\n"
 
 	// Add command
@@ -31,6 +36,15 @@ You can specify a parent in three ways:
 	msgCleanShort = "Remove finished todos"
 	msgCleanLong  = "Remove all todos marked as done from the collection."
 
+	// Branch-clean command
+	msgBranchCleanUse   = "branch-clean"
+	msgBranchCleanShort = "Archive the todos of deleted git branches"
+	msgBranchCleanLong  = `Archive the todos tagged with git branches that no longer exist locally, with the todos under them.
+
+They are moved to an archive next to the store (.todos.archive.json for .todos.json), which can be listed with:
+
+  too list -p .todos.archive.json --all`
+
 	// Edit command
 	msgEditUse   = "edit <position> <text>"
 	msgEditShort = "Edit the text of an existing todo"
@@ -39,17 +53,51 @@ You can specify a parent in three ways:
 	// Init command
 	msgInitUse   = "init"
 	msgInitShort = "Initialize a new todo collection"
-	msgInitLong  = "Initialize a new todo collection in the specified location or the default location (~/.todos.json)."
+	msgInitLong  = `Initialize a new todo collection in the specified location or the default location (~/.todos.json).
+
+In a git repository, --shared keeps the todos in git for the whole team: .todos.json is left out of .gitignore and written one todo per block, in a fixed order, so that diffs stay small. The choice is remembered in .gitattributes (.todos.json too-shared), to commit along with the todos.
+
+--install-merge-driver lets git merge .todos.json by todo instead of by line (see too merge-driver). The driver is registered in .git/config, so each clone installs it, and .gitattributes, to commit.`
+
+	// Merge-driver command
+	msgMergeDriverUse   = "merge-driver <base> <ours> <theirs>"
+	msgMergeDriverShort = "Merge two versions of a todo file, for git"
+	msgMergeDriverLong  = `Merge two versions of a todo file made from a common base, writing the result over ours. Git runs it for .todos.json once "too init --install-merge-driver" registered it as:
+
+  too merge-driver %O %A %B
+
+Todos are matched by UUID and merged field by field. When both sides changed the same field, done wins over pending, and for the text and notes, both versions are kept: theirs as a copy of the todo, right after it.`
 
 	// List command
 	msgListUse   = "list"
 	msgListShort = "List all todos"
-	msgListLong  = "List all todos in the collection."
+	msgListLong  = `List all todos in the collection.
+
+  too list --everywhere   # every project's todos, referenced as api:3
+  too list --with-global  # this project's todos, then the global ones as g:1
+  too list --recursive    # this list, then the sub-scopes below, as services/api:1
+
+Set TODO_WITH_GLOBAL=1 to always show the global todos along with the project's.`
+
+	// Projects command
+	msgProjectsUse   = "projects"
+	msgProjectsShort = "List the projects too has been used in"
+	msgProjectsLong  = `List the projects too has been used in, with their pending and done counts. The current project is marked with a *.
+
+Projects are recorded the first time too is used inside them. Their names qualify references from anywhere:
+
+  too list --everywhere
+  too complete api:3`
 
 	// Search command
 	msgSearchUse   = "search <query>"
 	msgSearchShort = "Search for todos"
-	msgSearchLong  = "Search for todos containing the specified text."
+	msgSearchLong  = `Search for todos containing the specified text. Matches are shown among their parents, with the matched text highlighted.
+
+  too search milk
+  too search --regex '^buy (milk|eggs)'
+  too search --fuzzy bymlk          # letters in order, closest matches first
+  too search --in notes invoice     # search the notes instead of the text`
 
 	// Complete command
 	msgCompleteUse   = "complete <positions...>"
@@ -64,7 +112,106 @@ You can specify a parent in three ways:
 	// Move command
 	msgMoveUse   = "move <source_path> <destination_parent_path>"
 	msgMoveShort = "Move a todo to a different parent"
-	msgMoveLong  = "Move a todo from one location to another in the hierarchy. Use dot notation for paths (e.g., 1.2). Use empty string \"\" for root level."
+	msgMoveLong  = `Move a todo from one location to another in the hierarchy. Use dot notation for paths (e.g., 1.2). Use empty string "" for root level.
+
+With --to-scope, the todo and everything under it go to the top level of another store, statuses and notes included:
+
+  too move 3 --to-scope global         # from this project to the global todos
+  too move g:2 --to-scope project      # from the global todos to this project
+  too move 1 --to-scope ~/work/.todos.json --copy`
+
+	// Import command
+	msgImportUse   = "import <format> [file]"
+	msgImportShort = "Import todos from another todo tool"
+	msgImportLong  = `Import todos from another tool's format. Reads from the given file, or from stdin when no file is given.
+
+  too import todotxt todo.txt
+  too import org todos.org
+  too import taskwarrior export.json   # from: task export > export.json
+  cat todo.txt | too import todotxt`
+
+	// Export command
+	msgExportUse   = "export"
+	msgExportShort = "Export all todos"
+	msgExportLong  = `Export all todos, done ones included, in the format given by --format (json by default).
+
+  too export --format todotxt > todo.txt
+  too export --format ics > todos.ics # subscribe to the file from a calendar app
+  too export --format org > todos.org
+  too export --format html > todos.html # static status page
+  too formats                         # lists every available format`
+
+	// Serve command
+	msgServeUse   = "serve"
+	msgServeShort = "Serve the todos over a local HTTP/JSON API"
+	msgServeLong  = `Serve the todo collection over a local HTTP API for editor and dashboard integrations. Responses are the same JSON that --format json prints.
+
+  GET    /todos                   list (?done=true, ?all=true)
+  GET    /search?q=<query>        search (?all=true)
+  POST   /todos                   add {"text": "...", "parent": "1"}
+  POST   /todos/<ref>/complete    complete
+  POST   /todos/<ref>/reopen      reopen
+  PATCH  /todos/<ref>             edit {"text": "..."}
+  POST   /todos/<ref>/move        move {"to": "2"}
+  POST   /clean                   clean
+
+Requests that change todos must be sent as application/json. Only requests addressed to the listen address, or another name of the loopback interface, and from no other origin are answered, so web pages can't drive the server.
+
+  too serve --listen 127.0.0.1:7117
+  curl -X POST localhost:7117/todos -H 'Content-Type: application/json' -d '{"text": "Buy milk"}'`
+	msgServeListening = "Serving %s on http://%s\n"
+
+	// Batch command
+	msgBatchUse   = "batch [file]"
+	msgBatchShort = "Run many commands in one transaction"
+	msgBatchLong  = `Run commands read from a file, or stdin when no file is given, as a single transaction. Each line is a command as you would type it after "too", or a JSON object:
+
+  complete 1 2
+  add --to 1 "Buy milk"
+  {"command": "move", "args": ["3", "1"]}
+
+References are resolved against the todos as they were before the batch started, so IDs don't shift between commands. If any command fails nothing is applied.`
+
+	// Shell command
+	msgShellUse   = "shell"
+	msgShellShort = "Interactive prompt for triage sessions"
+	msgShellLong  = `Start an interactive prompt that keeps the todo store open between commands. It takes the same verbs and aliases as the command line, without the "too":
+
+  too> a Buy milk
+  too> c 1
+  too> m 2 1
+  too> list --all
+
+Text without a verb adds a todo and an empty line lists. Use the up and down arrows for history, "help" for the verbs and "exit" or ctrl-d to leave.`
+	msgShellPrompt     = "too> "
+	msgShellHelpHeader = "Commands:\n"
+	msgShellHelpFooter = "  help                         Show this help\n  exit                         Leave the shell\n"
+
+	// UI command
+	msgUIUse   = "ui"
+	msgUIShort = "Full-screen interactive view of the todos"
+	msgUILong  = `Open a full-screen, keyboard driven view of the todo tree. Changes are saved as you make them.
+
+  j/k, arrows   move
+  space         toggle done
+  a / A         add a sibling / a child
+  e             edit the text
+  > / <         indent / outdent
+  / , n / N     search, next / previous match
+  tab, h / l    fold / unfold subtrees
+  q             quit`
+
+	// RPC command
+	msgRPCUse   = "rpc"
+	msgRPCShort = "Speak JSON-RPC 2.0 on stdin/stdout for editor plugins"
+	msgRPCLong  = `Run a long-lived JSON-RPC 2.0 session on stdin/stdout, one message per line. Methods are the command names and aliases (add, complete, list, ...), results are the same JSON that --format json prints.
+
+Params are an array of arguments, or an object with the arguments under "args" and options as the other members:
+
+  {"jsonrpc": "2.0", "id": 1, "method": "add", "params": {"args": ["Milk"], "parent": "1"}}
+  {"jsonrpc": "2.0", "id": 2, "method": "complete", "params": ["1.1"]}
+
+A "collectionChanged" notification is sent whenever the data file is changed by another process.`
 )
 
 // Flag descriptions
@@ -77,13 +224,35 @@ const (
 	msgFlagQuiet      = "quiet output (shows only confirmation message)"
 	msgFlagLoud       = "loud output (shows full todo list after command)"
 	msgFlagContextual = "use contextual view for change output"
+	msgFlagNoInput    = "never prompt, fail on ambiguous references instead of asking which todo was meant"
+	msgFlagShowUID    = "show each todo's permanent short ID (@a3f9), usable wherever a reference is"
+	msgFlagBranch     = "keep todos per git branch: tag new todos with the current branch and list only its todos"
 
 	// List command flags
 	msgFlagDone = "print done todos"
 	msgFlagAll  = "print all todos"
 
+	msgFlagEverywhere = "list the todos of every project, under project headings"
+	msgFlagWithGlobal = "list the global todos after the project's, referenced as g:1"
+	msgFlagRecursive  = "also list the sub-scopes below the current directory, under their paths"
+
 	// Search command flags
 	msgFlagCaseSensitive = "Perform case-sensitive search"
+	msgFlagRegex         = "treat the query as a regular expression"
+	msgFlagFuzzy         = "match the query's letters in order, best matches first"
+	msgFlagSearchIn      = "what to search: text or notes"
+	msgFlagSearchAll     = "search done todos too"
+
+	// Move command flags
+	msgFlagToScope = "move to another store: global, project or a store path"
+	msgFlagCopy    = "with --to-scope, copy instead of moving"
+
+	// Init command flags
+	msgFlagShared             = "commit the project's todos to git in a diff-friendly layout instead of ignoring them"
+	msgFlagInstallMergeDriver = "register the todo merge driver with git, in .git/config and .gitattributes"
+
+	// Serve command flags
+	msgFlagListen = "address to listen on, keep it on localhost: the API has no authentication"
 )
 
 // Error messages
@@ -91,6 +260,21 @@ const (
 	msgCommandFailed = "Command failed"
 )
 
+// Picker prompts
+const (
+	msgPickAmbiguous  = "Several todos match %q:"
+	msgPickComplete   = "Complete which todo?"
+	msgPickReopen     = "Reopen which todo?"
+	msgPickEdit       = "Edit which todo?"
+	msgPickMove       = "Move which todo?"
+	msgPickMoveTo     = "Move %q under which todo?"
+	msgPickTopLevel   = "(top level)"
+	msgPickPrompt     = "Pick a number, or type to narrow the list (enter cancels): "
+	msgPickOutOfRange = "There is no %d in the list.\n"
+	msgPickNoMatch    = "Nothing matches %q.\n"
+	msgPickEditText   = "New text for %q: "
+)
+
 // Command aliases
 var (
 	aliasesAdd      = []string{"a", "new", "create"}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// errPickCancelled is returned when the user leaves the picker without
+// choosing a todo
+var errPickCancelled = errors.New("no todo picked")
+
+// interactive reports whether todos can be picked by asking the user, which
+// needs a terminal on stdin and no --no-input
+func interactive() bool {
+	return !noInputFlag && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// pickableArgs relaxes validate so that commands can run without references
+// when interactive, the todo being picked instead
+func pickableArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && interactive() {
+			return nil
+		}
+		return validate(cmd, args)
+	}
+}
+
+// picker asks the user to choose among todos. The list and prompts go to out
+// so that stdout keeps only the command's output.
+type picker struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// newPicker creates a picker reading answers from in
+func newPicker(in io.Reader, out io.Writer) *picker {
+	return &picker{in: bufio.NewReader(in), out: out}
+}
+
+// sharedPicker keeps one buffered reader on stdin across prompts
+var sharedPicker *picker
+
+// stdinPicker is the picker used by commands
+func stdinPicker() *picker {
+	if sharedPicker == nil {
+		sharedPicker = newPicker(os.Stdin, os.Stderr)
+	}
+	return sharedPicker
+}
+
+// pick shows the candidates numbered and returns the chosen one. Typing text
+// instead of a number narrows the list to the todos that fuzzily match it.
+// With topLevel, 0 picks the top level and pick returns a nil todo.
+func (p *picker) pick(title string, candidates []*models.Todo, topLevel bool) (*models.Todo, error) {
+	if len(candidates) == 0 && !topLevel {
+		return nil, fmt.Errorf("no todos to pick from")
+	}
+
+	shown := candidates
+	for {
+		fmt.Fprintln(p.out, title)
+		if topLevel {
+			fmt.Fprintf(p.out, "  %2d) %s\n", 0, msgPickTopLevel)
+		}
+		for i, todo := range shown {
+			fmt.Fprintf(p.out, "  %2d) %-6s %s\n", i+1, todo.PositionPath, todo.Text)
+		}
+		fmt.Fprint(p.out, msgPickPrompt)
+
+		answer, err := p.readLine()
+		if err != nil || answer == "" {
+			return nil, errPickCancelled
+		}
+
+		if n, convErr := strconv.Atoi(answer); convErr == nil {
+			switch {
+			case n == 0 && topLevel:
+				return nil, nil
+			case n >= 1 && n <= len(shown):
+				return shown[n-1], nil
+			}
+			fmt.Fprintf(p.out, msgPickOutOfRange, n)
+			continue
+		}
+
+		var narrowed []*models.Todo
+		for _, todo := range candidates {
+			if fuzzyMatch(answer, todo.Text) {
+				narrowed = append(narrowed, todo)
+			}
+		}
+		switch len(narrowed) {
+		case 0:
+			fmt.Fprintf(p.out, msgPickNoMatch, answer)
+		case 1:
+			return narrowed[0], nil
+		default:
+			shown = narrowed
+		}
+	}
+}
+
+// ask prints prompt and returns the answer, or errPickCancelled when there
+// is none
+func (p *picker) ask(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	answer, err := p.readLine()
+	if err != nil || answer == "" {
+		return "", errPickCancelled
+	}
+	return answer, nil
+}
+
+// readLine reads one trimmed line, accepting a last line without a newline
+func (p *picker) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// fuzzyMatch reports whether the letters of query appear in text in order,
+// ignoring case and spaces
+func fuzzyMatch(query, text string) bool {
+	target := []rune(strings.ToLower(text))
+	i := 0
+	for _, r := range strings.ToLower(query) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		for i < len(target) && target[i] != r {
+			i++
+		}
+		if i == len(target) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// executeInteractive runs a unified command and, when interactive, lets the
+// user resolve ambiguous references by picking one of the matches. The
+// command is then run again with the picked todo's UUID in place of the
+// reference. Otherwise the ambiguity error is returned as is.
+func executeInteractive(cmdName string, args []string, opts map[string]interface{}) (*too.ChangeResult, error) {
+	for {
+		result, err := too.ExecuteUnifiedCommand(cmdName, args, opts)
+
+		var ambiguous *too.Error
+		if err == nil || !errors.As(err, &ambiguous) || ambiguous.Kind != too.ErrAmbiguous || !interactive() {
+			return result, err
+		}
+
+		chosen, pickErr := stdinPicker().pick(fmt.Sprintf(msgPickAmbiguous, ambiguous.Ref), ambiguous.Candidates, false)
+		if pickErr != nil {
+			return nil, pickErr
+		}
+		if !replaceRef(cmdName, args, opts, ambiguous.Ref, chosen.UID) {
+			return nil, err
+		}
+	}
+}
+
+// replaceRef swaps ref for uid where the command takes references: the
+// parent option and the leading reference arguments, including inside
+// symbolic references like "next:ref". It reports whether ref was found.
+func replaceRef(cmdName string, args []string, opts map[string]interface{}, ref, uid string) bool {
+	if parent, ok := opts["parent"].(string); ok {
+		if replaced, found := swapRef(parent, ref, uid); found {
+			opts["parent"] = replaced
+			return true
+		}
+	}
+
+	for i := 0; i < too.ReferenceArgCount(cmdName, len(args)); i++ {
+		if replaced, found := swapRef(args[i], ref, uid); found {
+			args[i] = replaced
+			return true
+		}
+	}
+	return false
+}
+
+// swapRef replaces ref with uid in arg, when arg is ref, a symbolic
+// reference relative to it or ref qualified with a project
+func swapRef(arg, ref, uid string) (string, bool) {
+	if arg == ref {
+		return uid, true
+	}
+	if project, rest, ok := too.SplitProjectRef(arg); ok {
+		if replaced, found := swapRef(rest, ref, uid); found {
+			return project + too.ProjectRefSeparator + replaced, true
+		}
+	}
+	prefix, inner, suffix := too.UnwrapSymbolicRef(arg)
+	if inner != ref {
+		return arg, false
+	}
+	return prefix + uid + suffix, true
+}
+
+// pickTodo lets the user choose one of the todos the list command shows with
+// opts, for commands run without a reference. Todos with the exclude UID are
+// left out, and topLevel is passed on to pick.
+func pickTodo(title string, opts map[string]interface{}, exclude string, topLevel bool) (*models.Todo, error) {
+	result, err := too.ExecuteUnifiedCommand("list", nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*models.Todo, 0, len(result.AllTodos))
+	for _, todo := range result.AllTodos {
+		if todo.UID != exclude {
+			candidates = append(candidates, todo)
+		}
+	}
+	return stdinPicker().pick(title, candidates, topLevel)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pickerTodos() []*models.Todo {
+	return []*models.Todo{
+		{UID: "a", PositionPath: "1", Text: "Buy milk"},
+		{UID: "b", PositionPath: "2", Text: "Buy oat milk"},
+		{UID: "c", PositionPath: "3", Text: "Buy bread"},
+	}
+}
+
+func TestPicker(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		topLevel bool
+		want     string // UID of the picked todo, "" for the top level
+		wantErr  error
+	}{
+		{name: "picks by number", input: "2\n", want: "b"},
+		{name: "narrows to a single match", input: "oat\n", want: "b"},
+		{name: "narrows then picks by number", input: "mlk\n2\n", want: "b"},
+		{name: "asks again when out of range", input: "7\n3\n", want: "c"},
+		{name: "asks again when nothing matches", input: "cheese\n1\n", want: "a"},
+		{name: "accepts a last line without newline", input: "3", want: "c"},
+		{name: "picks the top level", input: "0\n", topLevel: true, want: ""},
+		{name: "zero is out of range without top level", input: "0\n1\n", want: "a"},
+		{name: "empty answer cancels", input: "\n", wantErr: errPickCancelled},
+		{name: "end of input cancels", input: "", wantErr: errPickCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			todo, err := newPicker(strings.NewReader(tt.input), &out).pick("Which?", pickerTodos(), tt.topLevel)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Nil(t, todo)
+			} else {
+				require.NotNil(t, todo)
+				assert.Equal(t, tt.want, todo.UID)
+			}
+		})
+	}
+
+	t.Run("lists the candidates with their positions", func(t *testing.T) {
+		var out bytes.Buffer
+		_, err := newPicker(strings.NewReader("1\n"), &out).pick("Which?", pickerTodos(), true)
+		require.NoError(t, err)
+
+		assert.Contains(t, out.String(), "Which?")
+		assert.Contains(t, out.String(), "0) "+msgPickTopLevel)
+		assert.Contains(t, out.String(), "2) 2      Buy oat milk")
+	})
+
+	t.Run("ask returns the trimmed answer", func(t *testing.T) {
+		var out bytes.Buffer
+		answer, err := newPicker(strings.NewReader("  Buy eggs \n"), &out).ask("Text: ")
+		require.NoError(t, err)
+		assert.Equal(t, "Buy eggs", answer)
+	})
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	assert.True(t, fuzzyMatch("oat", "Buy oat milk"))
+	assert.True(t, fuzzyMatch("bym", "Buy milk"))
+	assert.True(t, fuzzyMatch("OAT MILK", "Buy oat milk"))
+	assert.False(t, fuzzyMatch("mb", "Buy milk"))
+	assert.False(t, fuzzyMatch("eggs", "Buy milk"))
+}
+
+func TestReplaceRef(t *testing.T) {
+	args := []string{"1", "milk", "milk"}
+	assert.True(t, replaceRef("complete", args, map[string]interface{}{}, "milk", "uid"))
+	assert.Equal(t, []string{"1", "uid", "milk"}, args)
+
+	// The text of an edit is never a reference
+	args = []string{"1", "milk"}
+	assert.False(t, replaceRef("edit", args, map[string]interface{}{}, "milk", "uid"))
+	assert.Equal(t, []string{"1", "milk"}, args)
+
+	opts := map[string]interface{}{"parent": "milk"}
+	args = []string{"milk"}
+	assert.True(t, replaceRef("add", args, opts, "milk", "uid"))
+	assert.Equal(t, "uid", opts["parent"])
+	assert.Equal(t, []string{"milk"}, args)
+
+	// References relative to the ambiguous one keep their relation
+	args = []string{"next:milk", "parent:milk^"}
+	assert.True(t, replaceRef("complete", args, map[string]interface{}{}, "milk", "uid"))
+	assert.Equal(t, []string{"next:uid", "parent:milk^"}, args)
+	opts = map[string]interface{}{"parent": "milk^"}
+	assert.True(t, replaceRef("add", []string{"x"}, opts, "milk", "uid"))
+	assert.Equal(t, "uid^", opts["parent"])
+}
+
+func TestExecuteInteractiveWithoutTerminal(t *testing.T) {
+	opts := map[string]interface{}{"collectionPath": filepath.Join(t.TempDir(), "test.json")}
+	for _, text := range []string{"Buy milk", "Buy bread"} {
+		_, err := too.ExecuteUnifiedCommand("add", []string{text}, opts)
+		require.NoError(t, err)
+	}
+
+	// Tests don't run on a terminal, so the ambiguity is reported as is
+	_, err := executeInteractive("complete", []string{"Buy"}, opts)
+	assert.Equal(t, too.ErrAmbiguous, too.KindOf(err))
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/arthur-debert/too/pkg/too/commands/projects"
+	"github.com/spf13/cobra"
+)
+
+var projectsCmd = &cobra.Command{
+	Use:     msgProjectsUse,
+	Short:   msgProjectsShort,
+	Long:    msgProjectsLong,
+	GroupID: "misc",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Call business logic
+		result, err := projects.Execute(projects.Options{
+			CollectionPath: resolveDataPath(cmd),
+		})
+		if err != nil {
+			return err
+		}
+
+		// Render the result
+		return renderToStdout(result)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(projectsCmd)
+}
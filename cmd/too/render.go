@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/arthur-debert/too/pkg/lipbalm"
+	"github.com/arthur-debert/too/pkg/too"
 	"github.com/arthur-debert/too/pkg/too/output"
 )
 
@@ -46,8 +47,14 @@ func render(writer io.Writer, format string, data interface{}) error {
 	return engine.Render(writer, format, data)
 }
 
-// renderToStdout renders data using the global format flag to stdout
+// renderToStdout renders data using the global format flag to stdout. The
+// warnings of a command result go to stderr.
 func renderToStdout(data interface{}) error {
+	if result, ok := data.(*too.ChangeResult); ok {
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+	}
 	return render(os.Stdout, formatFlag, data)
 }
 
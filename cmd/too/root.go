@@ -1,6 +1,9 @@
 package main
 
 import (
+	"os"
+	"strconv"
+
 	"github.com/arthur-debert/too/internal/version"
 	"github.com/arthur-debert/too/pkg/logging"
 	"github.com/arthur-debert/too/pkg/too"
@@ -14,6 +17,9 @@ var (
 	formatFlag     string
 	contextualView bool
 	globalFlag     bool
+	noInputFlag    bool
+	showUIDFlag    bool
+	branchFlag     bool
 
 	rootCmd = &cobra.Command{
 		Use:     "too",
@@ -40,6 +46,24 @@ var (
 				config.Display.UseContextualChangeView = contextualView
 				too.SetConfig(config)
 			}
+			if withGlobal, _ := strconv.ParseBool(os.Getenv("TODO_WITH_GLOBAL")); withGlobal {
+				config := too.GetConfig()
+				config.Display.ShowGlobalTodos = true
+				too.SetConfig(config)
+			}
+			if branches, _ := strconv.ParseBool(os.Getenv("TODO_BRANCHES")); branches || branchFlag {
+				config := too.GetConfig()
+				config.Branches = true
+				too.SetConfig(config)
+			}
+			if showUIDFlag {
+				config := too.GetConfig()
+				config.Display.ShowShortIDs = true
+				too.SetConfig(config)
+			}
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			recordProject()
 		},
 	}
 )
@@ -80,6 +104,14 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&formatFlag, "format", "f", "term", msgFlagFormat)
 	rootCmd.PersistentFlags().BoolVar(&contextualView, "contextual", false, msgFlagContextual)
 	rootCmd.PersistentFlags().BoolVarP(&globalFlag, "global", "g", false, "Use global todo storage instead of project-specific")
+	rootCmd.PersistentFlags().BoolVar(&noInputFlag, "no-input", false, msgFlagNoInput)
+	rootCmd.PersistentFlags().BoolVar(&showUIDFlag, "show-uid", false, msgFlagShowUID)
+	rootCmd.PersistentFlags().BoolVar(&branchFlag, "branch", false, msgFlagBranch)
+
+	// Bad flags are usage errors, reported with the validation exit code
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return too.NewValidationError("%s", err.Error())
+	})
 
 	// Setup custom help
 	setupHelp()
@@ -9,18 +9,34 @@ import (
 // It checks if we should inject "add" or "list" based on parsed args
 func handleNakedExecution() error {
 	// Get the args that Cobra parsed (excluding the program name)
-	args := os.Args[1:]
+	command := nakedCommand(os.Args[1:])
+	if command == "" {
+		// Let these pass through normally
+		return nil
+	}
+	
+	// Inject the appropriate command
+	newArgs := append([]string{os.Args[0], command}, os.Args[1:]...)
 	
+	// Update os.Args
+	os.Args = newArgs
+	
+	// Return nil to indicate we should retry
+	return nil
+}
+
+// nakedCommand decides which command args without a verb stand for: "add"
+// when there is text, "list" when there are only flags, and "" for help and
+// version requests that shouldn't trigger naked execution
+func nakedCommand(args []string) string {
 	// Check for special cases that shouldn't trigger naked execution
 	for _, arg := range args {
 		if arg == "-h" || arg == "--help" || arg == "--version" {
-			// Let these pass through normally
-			return nil
+			return ""
 		}
 	}
 	
 	// Look for any non-flag arguments, properly handling flag values
-	hasArgs := false
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		
@@ -51,26 +67,12 @@ func handleNakedExecution() error {
 			continue
 		}
 		
-		// Found a non-flag argument
-		hasArgs = true
-		break
+		// Found a non-flag argument -> "add"
+		return "add"
 	}
 	
-	// Inject the appropriate command
-	var newArgs []string
-	if hasArgs {
-		// Has arguments -> inject "add"
-		newArgs = append([]string{os.Args[0], "add"}, os.Args[1:]...)
-	} else {
-		// No arguments -> inject "list"
-		newArgs = append([]string{os.Args[0], "list"}, os.Args[1:]...)
-	}
-	
-	// Update os.Args
-	os.Args = newArgs
-	
-	// Return nil to indicate we should retry
-	return nil
+	// No arguments -> "list"
+	return "list"
 }
 
 // isUnknownCommandError checks if the error is due to an unknown command
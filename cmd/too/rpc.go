@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/commands/datapath"
+	"github.com/arthur-debert/too/pkg/too/rpc"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var rpcCmd = &cobra.Command{
+	Use:     msgRPCUse,
+	Short:   msgRPCShort,
+	Long:    msgRPCLong,
+	GroupID: "misc",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get collection path from flag
+		collectionPath := resolveDataPath(cmd)
+
+		// Ensure gitignore is updated for project scope. Stdout belongs to
+		// the protocol, so warn on stderr.
+		if err := datapath.EnsureProjectGitignore(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not update .gitignore: %v\n", err)
+		}
+
+		// One engine serves every call for the whole session
+		engine, err := too.NewNanoEngine(collectionPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := engine.Close(); err != nil {
+				log.Debug().Err(err).Msg("error closing engine")
+			}
+		}()
+
+		server, err := rpc.New(engine, collectionPath)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return server.Serve(ctx, os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rpcCmd)
+}
@@ -21,13 +21,22 @@ var searchCmd = &cobra.Command{
 		// Get collection path from flag
 		collectionPath := resolveDataPath(cmd)
 
-		// Get case-sensitive flag (not currently used in unified command)
-		// caseSensitive, _ := cmd.Flags().GetBool("case-sensitive")
+		// Get matching flags
+		caseSensitive, _ := cmd.Flags().GetBool("case-sensitive")
+		regex, _ := cmd.Flags().GetBool("regex")
+		fuzzy, _ := cmd.Flags().GetBool("fuzzy")
+		in, _ := cmd.Flags().GetString("in")
+		all, _ := cmd.Flags().GetBool("all")
 
 		// Call business logic using unified command
 		opts := map[string]interface{}{
 			"collectionPath": collectionPath,
 			"query":          query,
+			"caseSensitive":  caseSensitive,
+			"regex":          regex,
+			"fuzzy":          fuzzy,
+			"in":             in,
+			"all":            all,
 		}
 		result, err := too.ExecuteUnifiedCommand("search", []string{query}, opts)
 		if err != nil {
@@ -41,5 +50,9 @@ var searchCmd = &cobra.Command{
 
 func init() {
 	searchCmd.Flags().BoolP("case-sensitive", "s", false, msgFlagCaseSensitive)
+	searchCmd.Flags().Bool("regex", false, msgFlagRegex)
+	searchCmd.Flags().Bool("fuzzy", false, msgFlagFuzzy)
+	searchCmd.Flags().String("in", too.SearchInText, msgFlagSearchIn)
+	searchCmd.Flags().BoolP("all", "a", false, msgFlagSearchAll)
 	rootCmd.AddCommand(searchCmd)
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/commands/datapath"
+	"github.com/arthur-debert/too/pkg/too/server"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var serveListen string
+
+var serveCmd = &cobra.Command{
+	Use:     msgServeUse,
+	Short:   msgServeShort,
+	Long:    msgServeLong,
+	GroupID: "misc",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get collection path from flag
+		collectionPath := resolveDataPath(cmd)
+
+		// Ensure gitignore is updated for project scope
+		if err := datapath.EnsureProjectGitignore(); err != nil {
+			// Log but don't fail
+			fmt.Printf("Warning: could not update .gitignore: %v\n", err)
+		}
+
+		// One engine serves every request for the whole session
+		engine, err := too.NewNanoEngine(collectionPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := engine.Close(); err != nil {
+				log.Debug().Err(err).Msg("error closing engine")
+			}
+		}()
+
+		listener, err := net.Listen("tcp", serveListen)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", serveListen, err)
+		}
+
+		handler, err := server.New(engine, listener.Addr().String())
+		if err != nil {
+			return err
+		}
+
+		httpServer := &http.Server{Handler: handler}
+
+		// Shut down cleanly on interrupt so the engine gets closed
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			_ = httpServer.Shutdown(context.Background())
+		}()
+
+		fmt.Printf(msgServeListening, collectionPath, listener.Addr())
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:7117", msgFlagListen)
+	rootCmd.AddCommand(serveCmd)
+}
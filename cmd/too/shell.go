@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/commands/batch"
+	"github.com/arthur-debert/too/pkg/too/commands/datapath"
+	"github.com/arthur-debert/too/pkg/too/output"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var shellCmd = &cobra.Command{
+	Use:     msgShellUse,
+	Short:   msgShellShort,
+	Long:    msgShellLong,
+	GroupID: "misc",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get collection path from flag
+		collectionPath := resolveDataPath(cmd)
+
+		// Ensure gitignore is updated for project scope
+		if err := datapath.EnsureProjectGitignore(); err != nil {
+			// Log but don't fail
+			fmt.Printf("Warning: could not update .gitignore: %v\n", err)
+		}
+
+		// One engine serves every command for the whole session
+		engine, err := too.NewNanoEngine(collectionPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := engine.Close(); err != nil {
+				log.Debug().Err(err).Msg("error closing engine")
+			}
+		}()
+
+		session := &shellSession{engine: engine, format: formatFlag}
+
+		// Plain line reading when input is not a terminal, e.g. piped scripts
+		stdin := int(os.Stdin.Fd())
+		if !term.IsTerminal(stdin) {
+			session.out = os.Stdout
+			return session.run(bufio.NewScanner(os.Stdin))
+		}
+
+		state, err := term.MakeRaw(stdin)
+		if err != nil {
+			return fmt.Errorf("failed to set up terminal: %w", err)
+		}
+		defer func() { _ = term.Restore(stdin, state) }()
+
+		// The terminal handles line editing and up/down history
+		terminal := term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{os.Stdin, os.Stdout}, msgShellPrompt)
+		if width, height, err := term.GetSize(stdin); err == nil {
+			_ = terminal.SetSize(width, height)
+		}
+		session.out = terminal
+
+		return session.run(&terminalLines{terminal: terminal})
+	},
+}
+
+// lineReader is the part of bufio.Scanner the shell loop needs
+type lineReader interface {
+	Scan() bool
+	Text() string
+	Err() error
+}
+
+// terminalLines adapts a term.Terminal to lineReader
+type terminalLines struct {
+	terminal *term.Terminal
+	line     string
+	err      error
+}
+
+func (t *terminalLines) Scan() bool {
+	t.line, t.err = t.terminal.ReadLine()
+	return t.err == nil
+}
+
+func (t *terminalLines) Text() string { return t.line }
+
+// Err reports read errors, end of input (ctrl-d) is not one
+func (t *terminalLines) Err() error {
+	if errors.Is(t.err, io.EOF) {
+		return nil
+	}
+	return t.err
+}
+
+// shellSession runs shell lines against one open engine
+type shellSession struct {
+	engine *too.NanoEngine
+	out    io.Writer
+	format string
+}
+
+// run reads and executes lines until exit or end of input
+func (s *shellSession) run(lines lineReader) error {
+	// Start with the current list so there is something to refer to
+	if _, err := s.execute(""); err != nil {
+		s.reportError(err)
+	}
+
+	for lines.Scan() {
+		quit, err := s.execute(lines.Text())
+		if err != nil {
+			s.reportError(err)
+		}
+		if quit {
+			return nil
+		}
+	}
+	return lines.Err()
+}
+
+// execute runs one shell line, reporting whether the session should end
+func (s *shellSession) execute(line string) (bool, error) {
+	line = strings.TrimSpace(line)
+
+	switch line {
+	case "exit", "quit":
+		return true, nil
+	case "help", "?":
+		fmt.Fprint(s.out, shellHelp())
+		return false, nil
+	}
+
+	// Lines without a known verb work like naked `too` invocations: text
+	// adds a todo and an empty line lists
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		line = "list"
+	} else if _, _, ok := too.LookupUnifiedCommand(fields[0]); !ok {
+		line = nakedCommand(fields) + " " + line
+	}
+
+	command, err := batch.ParseLine(line)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := too.ExecuteUnifiedCommandWithEngine(s.engine, command.Name, command.Args, command.Options)
+	if err != nil {
+		return false, err
+	}
+
+	return false, s.render(result)
+}
+
+// render shows a result, in the contextual view when the command changed
+// something so the affected todo stays in focus
+func (s *shellSession) render(result *too.ChangeResult) error {
+	var data interface{} = result
+	if s.format == "term" && len(result.AffectedTodos) > 0 && result.Command != "clean" {
+		data = &output.ChangeResultContextual{ChangeResult: result}
+	}
+	return render(s.out, s.format, data)
+}
+
+func (s *shellSession) reportError(err error) {
+	if renderErr := renderError(s.out, s.format, err); renderErr != nil {
+		fmt.Fprintf(s.out, "Error: %v\n", err)
+	}
+}
+
+// shellHelp lists the verbs the shell accepts
+func shellHelp() string {
+	names := make([]string, 0, len(too.UnifiedCommands))
+	for name := range too.UnifiedCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(msgShellHelpHeader)
+	for _, name := range names {
+		cmd := too.UnifiedCommands[name]
+		verb := name
+		if len(cmd.Aliases) > 0 {
+			verb += " (" + strings.Join(cmd.Aliases, ", ") + ")"
+		}
+		fmt.Fprintf(&b, "  %-28s %s\n", verb, cmd.Description)
+	}
+	b.WriteString(msgShellHelpFooter)
+	return b.String()
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestShell(t *testing.T, format string) (*shellSession, *bytes.Buffer) {
+	t.Helper()
+
+	engine, err := too.NewNanoEngine(filepath.Join(t.TempDir(), "test.json"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = engine.Close() })
+
+	var out bytes.Buffer
+	return &shellSession{engine: engine, out: &out, format: format}, &out
+}
+
+func TestShellSession(t *testing.T) {
+	t.Run("runs verbs, aliases and naked text on one engine", func(t *testing.T) {
+		session, out := newTestShell(t, "term")
+
+		input := strings.Join([]string{
+			"a Groceries",
+			"Call mom",
+			`add --to 1 "Buy milk"`,
+			"c 1.1",
+			"e mom Call dad",
+			"list --all",
+			"exit",
+			"a never runs",
+		}, "\n")
+		require.NoError(t, session.run(bufio.NewScanner(strings.NewReader(input))))
+
+		todos, err := session.engine.GetTodos(nil)
+		require.NoError(t, err)
+		texts := make([]string, 0, len(todos))
+		for _, todo := range todos {
+			texts = append(texts, todo.Text)
+		}
+		assert.ElementsMatch(t, []string{"Groceries", "Buy milk", "Call dad"}, texts)
+
+		assert.Contains(t, out.String(), "Call dad")
+		assert.NotContains(t, out.String(), "never runs")
+	})
+
+	t.Run("keeps going after errors", func(t *testing.T) {
+		session, out := newTestShell(t, "json")
+
+		input := "c 5\nedit\nadd Still works"
+		require.NoError(t, session.run(bufio.NewScanner(strings.NewReader(input))))
+
+		assert.Contains(t, out.String(), `"error"`)
+		todos, err := session.engine.GetTodos(nil)
+		require.NoError(t, err)
+		require.Len(t, todos, 1)
+		assert.Equal(t, "Still works", todos[0].Text)
+	})
+
+	t.Run("lists the verbs on help", func(t *testing.T) {
+		session, out := newTestShell(t, "term")
+
+		quit, err := session.execute("help")
+		require.NoError(t, err)
+		assert.False(t, quit)
+		assert.Contains(t, out.String(), "complete (c)")
+		assert.Contains(t, out.String(), "exit")
+	})
+}
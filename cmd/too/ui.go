@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/commands/datapath"
+	"github.com/arthur-debert/too/pkg/too/tui"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var uiCmd = &cobra.Command{
+	Use:     msgUIUse,
+	Short:   msgUIShort,
+	Long:    msgUILong,
+	GroupID: "misc",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get collection path from flag
+		collectionPath := resolveDataPath(cmd)
+
+		// Ensure gitignore is updated for project scope
+		if err := datapath.EnsureProjectGitignore(); err != nil {
+			// Log but don't fail
+			fmt.Printf("Warning: could not update .gitignore: %v\n", err)
+		}
+
+		engine, err := too.NewNanoEngine(collectionPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := engine.Close(); err != nil {
+				log.Debug().Err(err).Msg("error closing engine")
+			}
+		}()
+
+		model, err := tui.New(engine)
+		if err != nil {
+			return err
+		}
+
+		return tui.Run(model, os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/arthur-debert/too/pkg/too/commands/datapath"
+	"github.com/arthur-debert/too/pkg/too/scope"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -161,6 +162,35 @@ func TestEnsureProjectGitignore(t *testing.T) {
 		assert.Contains(t, string(content), ".todos.json")
 	})
 
+	t.Run("ignores sub-scope stores unless the repository shares them", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(tmpDir, ".git"), 0755))
+		subDir := filepath.Join(tmpDir, "web")
+		require.NoError(t, os.Mkdir(subDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(subDir, ".todos.json"), []byte("{}"), 0644))
+
+		origWd, _ := os.Getwd()
+		if err := os.Chdir(subDir); err != nil {
+			t.Fatalf("Failed to change directory: %v", err)
+		}
+		defer func() {
+			if err := os.Chdir(origWd); err != nil {
+				t.Errorf("Failed to restore working directory: %v", err)
+			}
+		}()
+
+		require.NoError(t, datapath.EnsureProjectGitignore())
+		content, err := os.ReadFile(filepath.Join(subDir, ".gitignore"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), ".todos.json\n")
+
+		require.NoError(t, os.Remove(filepath.Join(subDir, ".gitignore")))
+		_, err = scope.SetShared(tmpDir)
+		require.NoError(t, err)
+		require.NoError(t, datapath.EnsureProjectGitignore())
+		assert.NoFileExists(t, filepath.Join(subDir, ".gitignore"))
+	})
+
 	t.Run("no error when not in git repo", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
@@ -34,6 +34,9 @@ func DefaultStyles() StyleMap {
 			Faint(true),
 		"highlighted": lipgloss.NewStyle().
 			Bold(true),
+		"match": lipgloss.NewStyle().
+			Bold(true).
+			Underline(true),
 		"subdued": lipgloss.NewStyle().
 			Foreground(ColorMuted),
 		"accent": lipgloss.NewStyle().
@@ -2,6 +2,7 @@ package lipbalm
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -125,6 +126,14 @@ func (e *RenderEngine) Render(w io.Writer, format string, data interface{}) erro
 	return err
 }
 
+// StructuredError is implemented by errors that carry machine-readable
+// details. RenderError adds the fields next to the error message, so
+// structured formats can expose them to scripts.
+type StructuredError interface {
+	error
+	ErrorFields() map[string]interface{}
+}
+
 // RenderError renders an error in the specified format
 func (e *RenderEngine) RenderError(w io.Writer, format string, err error) error {
 	if format == "" {
@@ -132,7 +141,15 @@ func (e *RenderEngine) RenderError(w io.Writer, format string, err error) error
 	}
 
 	// Use a simple map for error representation
-	errorData := map[string]string{"error": err.Error()}
+	errorData := map[string]interface{}{"error": err.Error()}
+	var structured StructuredError
+	if errors.As(err, &structured) {
+		for key, value := range structured.ErrorFields() {
+			if key != "error" {
+				errorData[key] = value
+			}
+		}
+	}
 	
 	// For terminal format, use template if available
 	if format == "term" && e.config.TemplateManager != nil {
@@ -226,6 +243,22 @@ type Formatter interface {
 	Render(data interface{}, config *Config) (string, error)
 }
 
+// registeredFormatters holds application formatters added through RegisterFormatter
+var (
+	registeredMu         sync.RWMutex
+	registeredFormatters []Formatter
+)
+
+// RegisterFormatter makes an application-specific formatter available alongside
+// the built-in ones. Registered formatters show up in every registry created
+// afterwards, including the ones behind HasFormat, ListFormats and GetFormatInfo.
+// Registering a name twice replaces the earlier formatter.
+func RegisterFormatter(f Formatter) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registeredFormatters = append(registeredFormatters, f)
+}
+
 // newDefaultRegistry creates a registry with built-in formatters
 func newDefaultRegistry() *FormatRegistry {
 	r := &FormatRegistry{
@@ -240,6 +273,13 @@ func newDefaultRegistry() *FormatRegistry {
 	r.Register(&PlainFormatter{})
 	r.Register(&TerminalFormatter{})
 
+	// Register application formatters on top of the built-ins
+	registeredMu.RLock()
+	for _, f := range registeredFormatters {
+		r.Register(f)
+	}
+	registeredMu.RUnlock()
+
 	return r
 }
 
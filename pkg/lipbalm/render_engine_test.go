@@ -2,6 +2,7 @@ package lipbalm_test
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -114,6 +115,27 @@ func TestRenderEngine_ErrorHandling(t *testing.T) {
 		assert.Contains(t, output, `"error":`)
 		assert.Contains(t, output, testErr.Error())
 	})
+
+	t.Run("RenderError with structured error", func(t *testing.T) {
+		var buf bytes.Buffer
+		testErr := fmt.Errorf("wrapped: %w", structuredError{})
+
+		err := engine.RenderError(&buf, "json", testErr)
+		require.NoError(t, err)
+
+		output := buf.String()
+		assert.Contains(t, output, `"error": "wrapped: not found"`)
+		assert.Contains(t, output, `"kind": "not_found"`)
+	})
+}
+
+// structuredError is a StructuredError for the tests
+type structuredError struct{}
+
+func (structuredError) Error() string { return "not found" }
+
+func (structuredError) ErrorFields() map[string]interface{} {
+	return map[string]interface{}{"kind": "not_found", "error": "ignored"}
 }
 
 func TestRenderEngine_Formats(t *testing.T) {
@@ -140,6 +162,46 @@ func TestRenderEngine_Formats(t *testing.T) {
 	})
 }
 
+// shoutFormatter is a minimal application formatter used to test registration
+type shoutFormatter struct{}
+
+func (f *shoutFormatter) Name() string        { return "shout" }
+func (f *shoutFormatter) Description() string { return "Upper-cased output" }
+
+func (f *shoutFormatter) Render(data interface{}, config *lipbalm.Config) (string, error) {
+	if s, ok := data.(string); ok {
+		return strings.ToUpper(s), nil
+	}
+	return "", nil
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	lipbalm.RegisterFormatter(&shoutFormatter{})
+
+	t.Run("visible through package helpers", func(t *testing.T) {
+		assert.True(t, lipbalm.HasFormat("shout"))
+		assert.Contains(t, lipbalm.ListFormats(), "shout")
+
+		found := false
+		for _, info := range lipbalm.GetFormatInfo() {
+			if info.Name == "shout" {
+				found = true
+				assert.Equal(t, "Upper-cased output", info.Description)
+			}
+		}
+		assert.True(t, found, "registered format should be described")
+	})
+
+	t.Run("renders through new engines", func(t *testing.T) {
+		engine := lipbalm.Quick()
+		var buf bytes.Buffer
+
+		err := engine.Render(&buf, "shout", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "HELLO", buf.String())
+	})
+}
+
 func TestRenderEngine_CSV(t *testing.T) {
 	engine := lipbalm.Quick()
 
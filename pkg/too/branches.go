@@ -0,0 +1,264 @@
+package too
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/scope"
+)
+
+// branchMode reports whether todos are kept per git branch, and the branch
+// in use: the "branch" option, or with Config.Branches the branch checked
+// out where the store is. On a detached HEAD the branch is empty and only
+// untagged todos are shown.
+func (e *NanoEngine) branchMode(opts map[string]interface{}) (string, bool) {
+	if branch, ok := opts["branch"].(string); ok && branch != "" {
+		return branch, true
+	}
+	if !GetConfig().Branches {
+		return "", false
+	}
+	return scope.CurrentBranch(filepath.Dir(e.adapter.Path())), true
+}
+
+// onBranch returns the todos that are untagged or tagged with branch.
+// Descendants of hidden todos are hidden with them.
+func onBranch(todos []*models.Todo, branch string) []*models.Todo {
+	byUID := make(map[string]*models.Todo, len(todos))
+	for _, todo := range todos {
+		byUID[todo.UID] = todo
+	}
+	var hidden func(todo *models.Todo) bool
+	hidden = func(todo *models.Todo) bool {
+		if todo.Branch != "" && todo.Branch != branch {
+			return true
+		}
+		parent, ok := byUID[todo.ParentID]
+		return ok && hidden(parent)
+	}
+
+	shown := make([]*models.Todo, 0, len(todos))
+	for _, todo := range todos {
+		if !hidden(todo) {
+			shown = append(shown, todo)
+		}
+	}
+	return shown
+}
+
+// branchPositions numbers the todos shown on branch (see onBranch) among
+// themselves, as if the hidden todos weren't there, and returns their
+// position paths keyed by UID
+func branchPositions(all []*models.Todo, branch string) map[string]string {
+	shown := onBranch(all, branch)
+	byUID := make(map[string]*models.Todo, len(shown))
+	for _, todo := range shown {
+		byUID[todo.UID] = todo
+	}
+
+	// Siblings of the same status count from 1, as in the full list
+	type group struct{ parent, prefix string }
+	groups := make(map[group][]*models.Todo)
+	for _, todo := range shown {
+		segment := todo.PositionPath[strings.LastIndex(todo.PositionPath, ".")+1:]
+		key := group{todo.ParentID, strings.TrimRight(segment, "0123456789")}
+		groups[key] = append(groups[key], todo)
+	}
+	segments := make(map[string]string, len(shown))
+	for key, siblings := range groups {
+		sortByPosition(siblings)
+		for i, todo := range siblings {
+			segments[todo.UID] = key.prefix + strconv.Itoa(i+1)
+		}
+	}
+
+	positions := make(map[string]string, len(shown))
+	var position func(todo *models.Todo) string
+	position = func(todo *models.Todo) string {
+		if path, ok := positions[todo.UID]; ok {
+			return path
+		}
+		path := segments[todo.UID]
+		if parent, ok := byUID[todo.ParentID]; ok {
+			path = position(parent) + "." + path
+		}
+		positions[todo.UID] = path
+		return path
+	}
+	for _, todo := range shown {
+		position(todo)
+	}
+	return positions
+}
+
+// numberOnBranch gives the todos of lists their positions on the engine's
+// branch, see branchPositions
+func (e *NanoEngine) numberOnBranch(lists ...[]*models.Todo) error {
+	all, err := e.adapter.List(true)
+	if err != nil {
+		return err
+	}
+	positions := branchPositions(all, e.branch)
+	for _, todos := range lists {
+		for _, todo := range todos {
+			if path, ok := positions[todo.UID]; ok {
+				todo.PositionPath = path
+			}
+		}
+	}
+	return nil
+}
+
+// resolveOnBranch finds the todo at the position path ref among the todos
+// shown on the engine's branch
+func (e *NanoEngine) resolveOnBranch(ref string) (string, error) {
+	all, err := e.adapter.List(true)
+	if err != nil {
+		return "", err
+	}
+	for uid, path := range branchPositions(all, e.branch) {
+		if path == ref {
+			return uid, nil
+		}
+	}
+	return "", NewNotFoundError(ref)
+}
+
+// shownOnBranch returns the UIDs of the todos shown on the engine's branch
+func (e *NanoEngine) shownOnBranch() (map[string]bool, error) {
+	all, err := e.adapter.List(true)
+	if err != nil {
+		return nil, err
+	}
+	shown := make(map[string]bool, len(all))
+	for _, todo := range onBranch(all, e.branch) {
+		shown[todo.UID] = true
+	}
+	return shown, nil
+}
+
+// ArchivePath is where CleanBranches keeps the todos of deleted branches
+// for the store at path: .todos.json archives to .todos.archive.json
+func ArchivePath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".archive" + filepath.Ext(path)
+}
+
+// CleanBranches moves the todos tagged with branches that no longer exist
+// locally, with everything under them, to the store's archive (see
+// ArchivePath). The store only loses them once the archive has them.
+func CleanBranches(opts map[string]interface{}) (*ChangeResult, error) {
+	collectionPath, _ := opts["collectionPath"].(string)
+	branches, err := scope.LocalBranches(filepath.Dir(collectionPath))
+	if err != nil {
+		return nil, NewValidationError("branch-clean needs a git repository: %v", err)
+	}
+	exists := make(map[string]bool, len(branches))
+	for _, branch := range branches {
+		exists[branch] = true
+	}
+
+	engine, err := NewNanoEngine(collectionPath)
+	if err != nil {
+		return nil, err
+	}
+	defer engine.Close()
+	engine.UseLastView()
+
+	all, err := engine.adapter.List(true)
+	if err != nil {
+		return nil, err
+	}
+
+	// The topmost todos of gone branches take their subtrees along
+	gone := func(todo *models.Todo) bool {
+		return todo.Branch != "" && !exists[todo.Branch]
+	}
+	byUID := make(map[string]*models.Todo, len(all))
+	for _, todo := range all {
+		byUID[todo.UID] = todo
+	}
+	var roots []*models.Todo
+	goneBranches := make(map[string]bool)
+	for _, todo := range all {
+		if !gone(todo) {
+			continue
+		}
+		goneBranches[todo.Branch] = true
+		topmost := true
+		for parent := byUID[todo.ParentID]; parent != nil; parent = byUID[parent.ParentID] {
+			if gone(parent) {
+				topmost = false
+				break
+			}
+		}
+		if topmost {
+			roots = append(roots, todo)
+		}
+	}
+
+	archivePath := ArchivePath(collectionPath)
+	var archived []*models.Todo
+	for _, root := range roots {
+		subtree := subtreeOf(all, root.UID)
+		if _, err := writeSubtree(archivePath, subtree); err != nil {
+			return nil, err
+		}
+		archived = append(archived, subtree...)
+	}
+	for _, root := range roots {
+		if err := engine.adapter.Delete(root.UID, true); err != nil {
+			return nil, fmt.Errorf("archived to %s, but removing the originals failed: %w", archivePath, storeError(err))
+		}
+	}
+	if len(roots) > 0 && engine.viewEnabled {
+		if err := engine.advanceLastView(); err != nil {
+			return nil, err
+		}
+	}
+
+	todos, err := engine.List(false)
+	if err != nil {
+		return nil, err
+	}
+	if engine.branch, engine.branchOnly = engine.branchMode(opts); engine.branchOnly {
+		todos = onBranch(todos, engine.branch)
+		if err := engine.numberOnBranch(todos, archived); err != nil {
+			return nil, err
+		}
+	}
+	if err := engine.assignShortIDs(todos, archived); err != nil {
+		return nil, err
+	}
+	total, done := engine.GetStats()
+	return &ChangeResult{
+		Command:       "branch-clean",
+		Message:       branchCleanMessage(archived, goneBranches, archivePath),
+		AffectedTodos: archived,
+		AllTodos:      todos,
+		TotalCount:    total,
+		DoneCount:     done,
+		Warnings:      engine.Warnings(),
+	}, nil
+}
+
+// branchCleanMessage describes what CleanBranches archived
+func branchCleanMessage(archived []*models.Todo, branches map[string]bool, archivePath string) string {
+	if len(archived) == 0 {
+		return "No todos of deleted branches"
+	}
+	names := make([]string, 0, len(branches))
+	for name := range branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	noun := "todos"
+	if len(archived) == 1 {
+		noun = "todo"
+	}
+	return fmt.Sprintf("Archived %d %s of deleted branches (%s) to %s", len(archived), noun, strings.Join(names, ", "), archivePath)
+}
@@ -0,0 +1,168 @@
+package too
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranches(t *testing.T) {
+	// newRepo creates a git repository with branches checked out on head,
+	// and returns the path of its store
+	newRepo := func(t *testing.T, head string, branches ...string) string {
+		t.Helper()
+		root := t.TempDir()
+		heads := filepath.Join(root, ".git", "refs", "heads")
+		require.NoError(t, os.MkdirAll(heads, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref: refs/heads/"+head+"\n"), 0644))
+		for _, branch := range branches {
+			require.NoError(t, os.WriteFile(filepath.Join(heads, branch), []byte("abc\n"), 0644))
+		}
+		return filepath.Join(root, ".todos.json")
+	}
+
+	add := func(t *testing.T, path, text, branch, parent string) *models.Todo {
+		t.Helper()
+		opts := map[string]interface{}{"collectionPath": path, "parent": parent}
+		if branch != "" {
+			opts["branch"] = branch
+		}
+		result, err := ExecuteUnifiedCommand("add", []string{text}, opts)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.AffectedTodos)
+		return result.AffectedTodos[0]
+	}
+
+	texts := func(todos []*models.Todo) []string {
+		var found []string
+		for _, todo := range todos {
+			found = append(found, todo.Text)
+		}
+		return found
+	}
+
+	withBranches := func(t *testing.T) {
+		t.Helper()
+		original := GetConfig()
+		config := *original
+		config.Branches = true
+		SetConfig(&config)
+		t.Cleanup(func() { SetConfig(original) })
+	}
+
+	t.Run("tags new todos with the branch", func(t *testing.T) {
+		path := newRepo(t, "main", "main")
+		assert.Equal(t, "feature", add(t, path, "Fix login", "feature", "").Branch)
+		assert.Empty(t, add(t, path, "Release", "", "").Branch)
+	})
+
+	t.Run("lists the branch's todos and untagged ones", func(t *testing.T) {
+		path := newRepo(t, "main", "main")
+		add(t, path, "Release", "", "")
+		add(t, path, "Fix login", "feature", "")
+		add(t, path, "Write tests", "", "2")
+		add(t, path, "Update docs", "main", "")
+
+		result, err := ExecuteUnifiedCommand("list", nil, map[string]interface{}{"collectionPath": path, "branch": "main"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Release", "Update docs"}, texts(result.AllTodos))
+
+		result, err = ExecuteUnifiedCommand("list", nil, map[string]interface{}{"collectionPath": path})
+		require.NoError(t, err)
+		assert.Len(t, result.AllTodos, 4)
+	})
+
+	t.Run("positions and references count the branch's todos only", func(t *testing.T) {
+		path := newRepo(t, "main", "main")
+		add(t, path, "Release", "", "")
+		fix := add(t, path, "Fix login", "feature", "")
+		add(t, path, "Pay rent", "", "")
+		add(t, path, "Update docs", "main", "")
+		opts := map[string]interface{}{"collectionPath": path, "branch": "main"}
+
+		result, err := ExecuteUnifiedCommand("list", nil, opts)
+		require.NoError(t, err)
+		var positions []string
+		for _, todo := range result.AllTodos {
+			positions = append(positions, todo.PositionPath)
+		}
+		assert.Equal(t, []string{"1", "2", "3"}, positions)
+
+		// "Fix login" sits between 1 and 2 in the store. Without the
+		// listing's view, the position resolves on the branch all the same.
+		require.NoError(t, os.Remove(lastViewPath(path)))
+		result, err = ExecuteUnifiedCommand("complete", []string{"2"}, opts)
+		require.NoError(t, err)
+		require.Len(t, result.AffectedTodos, 1)
+		assert.Equal(t, "Pay rent", result.AffectedTodos[0].Text)
+
+		all, err := ExecuteUnifiedCommand("list", nil, map[string]interface{}{"collectionPath": path, "all": true})
+		require.NoError(t, err)
+		for _, todo := range all.AllTodos {
+			if todo.UID == fix.UID {
+				assert.Equal(t, models.StatusPending, todo.GetStatus())
+			}
+		}
+
+		for _, ref := range []string{"Fix login", "@" + compactUUID(fix.UID)[:8]} {
+			_, err = ExecuteUnifiedCommand("complete", []string{ref}, opts)
+			assert.Equal(t, ErrNotFound, KindOf(err), ref)
+		}
+	})
+
+	t.Run("the config reads the branch from HEAD", func(t *testing.T) {
+		path := newRepo(t, "feature", "main", "feature")
+		withBranches(t)
+		todo := add(t, path, "Fix login", "", "")
+		assert.Equal(t, "feature", todo.Branch)
+		add(t, path, "Update docs", "main", "")
+
+		result, err := ExecuteUnifiedCommand("list", nil, map[string]interface{}{"collectionPath": path})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Fix login"}, texts(result.AllTodos))
+	})
+
+	t.Run("branch-clean archives the todos of deleted branches", func(t *testing.T) {
+		path := newRepo(t, "main", "main")
+		add(t, path, "Release", "", "")
+		add(t, path, "Fix login", "feature", "")
+		add(t, path, "Write tests", "", "2")
+		add(t, path, "Update docs", "main", "")
+		_, err := ExecuteUnifiedCommand("complete", []string{"2.1"}, map[string]interface{}{"collectionPath": path})
+		require.NoError(t, err)
+
+		result, err := CleanBranches(map[string]interface{}{"collectionPath": path})
+		require.NoError(t, err)
+		assert.Equal(t, "branch-clean", result.Command)
+		assert.Contains(t, result.Message, "Archived 2 todos of deleted branches (feature)")
+		assert.Equal(t, []string{"Fix login", "Write tests"}, texts(result.AffectedTodos))
+		assert.Equal(t, []string{"Release", "Update docs"}, texts(result.AllTodos))
+
+		archive := ArchivePath(path)
+		assert.Equal(t, filepath.Join(filepath.Dir(path), ".todos.archive.json"), archive)
+		archived, err := ExecuteUnifiedCommand("list", nil, map[string]interface{}{"collectionPath": archive, "all": true})
+		require.NoError(t, err)
+		require.Len(t, archived.AllTodos, 2)
+		byText := make(map[string]*models.Todo)
+		for _, todo := range archived.AllTodos {
+			byText[todo.Text] = todo
+		}
+		assert.Equal(t, "feature", byText["Fix login"].Branch)
+		assert.Equal(t, byText["Fix login"].UID, byText["Write tests"].ParentID)
+		assert.Equal(t, models.StatusDone, byText["Write tests"].GetStatus())
+
+		result, err = CleanBranches(map[string]interface{}{"collectionPath": path})
+		require.NoError(t, err)
+		assert.Equal(t, "No todos of deleted branches", result.Message)
+	})
+
+	t.Run("branch-clean needs git", func(t *testing.T) {
+		_, err := CleanBranches(map[string]interface{}{"collectionPath": filepath.Join(t.TempDir(), ".todos.json")})
+		require.Error(t, err)
+		assert.Equal(t, ErrValidation, KindOf(err))
+	})
+}
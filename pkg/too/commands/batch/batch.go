@@ -0,0 +1,436 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/store"
+	"github.com/rs/zerolog/log"
+)
+
+// Command is one command read from the batch input
+type Command struct {
+	Line    int
+	Name    string
+	Args    []string
+	Options map[string]interface{}
+}
+
+// flag is a command line flag a line can give, and the option it sets
+type flag struct {
+	name   string
+	short  string
+	option string
+	value  bool
+}
+
+// commandFlags are the flags each command takes in the line format, the
+// ones it takes on the command line. Any other flag is an error.
+var commandFlags = map[string][]flag{
+	"add": {
+		{name: "to", option: "parent", value: true},
+	},
+	"list": {
+		{name: "done", short: "d", option: "done"},
+		{name: "all", short: "a", option: "all"},
+	},
+	"search": {
+		{name: "case-sensitive", short: "s", option: "caseSensitive"},
+		{name: "regex", option: "regex"},
+		{name: "fuzzy", option: "fuzzy"},
+		{name: "in", option: "in", value: true},
+		{name: "all", short: "a", option: "all"},
+	},
+}
+
+// lookupFlag finds a flag of a command by its long or, with short set, its
+// one letter name
+func lookupFlag(command, name string, short bool) (flag, bool) {
+	for _, f := range commandFlags[command] {
+		if (short && f.short == name) || (!short && f.name == name) {
+			return f, true
+		}
+	}
+	return flag{}, false
+}
+
+// Parse reads batch commands, one per line. A line is either a command as
+// typed on the command line (`complete 1 2`, `add --to 1 "Buy milk"`) or
+// a JSON object with the command name under "command", the arguments under
+// "args" and options as the other members. Blank lines and lines starting
+// with # are ignored.
+func Parse(r io.Reader) ([]Command, error) {
+	var commands []Command
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		command, err := ParseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		command.Line = lineNumber
+		commands = append(commands, command)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch input: %w", err)
+	}
+
+	return commands, nil
+}
+
+// ParseLine parses a single command in either of the formats Parse accepts
+func ParseLine(line string) (Command, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return Command{}, fmt.Errorf("empty command")
+	}
+	if strings.HasPrefix(line, "{") {
+		return parseJSONLine(line)
+	}
+	return parseTextLine(line)
+}
+
+func parseJSONLine(line string) (Command, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return Command{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	name, _ := fields["command"].(string)
+	if name == "" {
+		return Command{}, fmt.Errorf("missing \"command\"")
+	}
+	delete(fields, "command")
+
+	command := Command{Name: name, Options: fields}
+	if raw, ok := fields["args"]; ok {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return Command{}, fmt.Errorf("\"args\" must be an array of strings")
+		}
+		for _, item := range list {
+			arg, ok := item.(string)
+			if !ok {
+				return Command{}, fmt.Errorf("\"args\" must be an array of strings")
+			}
+			command.Args = append(command.Args, arg)
+		}
+		delete(fields, "args")
+	}
+
+	return command, nil
+}
+
+func parseTextLine(line string) (Command, error) {
+	words, err := splitWords(line)
+	if err != nil {
+		return Command{}, err
+	}
+
+	command := Command{Name: words[0], Options: map[string]interface{}{}}
+	cmd, name, ok := too.LookupUnifiedCommand(command.Name)
+	if !ok {
+		return Command{}, fmt.Errorf("unknown command: %s", command.Name)
+	}
+
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case word == "--":
+			command.Args = append(command.Args, words[i+1:]...)
+			i = len(words)
+		case strings.HasPrefix(word, "--"):
+			key, value, hasValue := strings.Cut(strings.TrimPrefix(word, "--"), "=")
+			f, ok := lookupFlag(name, key, false)
+			if !ok {
+				return Command{}, fmt.Errorf("unknown flag: --%s", key)
+			}
+			if f.value && !hasValue {
+				if i+1 >= len(words) {
+					return Command{}, fmt.Errorf("--%s requires a value", key)
+				}
+				i++
+				value, hasValue = words[i], true
+			}
+			if err := setFlag(command.Options, f, "--"+key, value, hasValue); err != nil {
+				return Command{}, err
+			}
+		case len(word) > 1 && word[0] == '-':
+			// Short flags can be grouped, as in -da
+			for _, letter := range word[1:] {
+				f, ok := lookupFlag(name, string(letter), true)
+				if !ok {
+					return Command{}, fmt.Errorf("unknown shorthand flag: -%c", letter)
+				}
+				if err := setFlag(command.Options, f, "-"+string(letter), "", false); err != nil {
+					return Command{}, err
+				}
+			}
+		default:
+			command.Args = append(command.Args, word)
+		}
+	}
+
+	// Commands taking text accept it unquoted, as on the command line
+	if cmd.RequiresText && len(command.Args) > 1 {
+		if name == "add" {
+			command.Args = []string{strings.Join(command.Args, " ")}
+		} else {
+			command.Args = []string{command.Args[0], strings.Join(command.Args[1:], " ")}
+		}
+	}
+
+	return command, nil
+}
+
+// setFlag sets the option of a flag given as spelled
+func setFlag(options map[string]interface{}, f flag, spelled, value string, hasValue bool) error {
+	if f.value {
+		if !hasValue {
+			return fmt.Errorf("%s requires a value", spelled)
+		}
+		options[f.option] = value
+		return nil
+	}
+	if !hasValue {
+		options[f.option] = true
+		return nil
+	}
+	on, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid value %q for %s", value, spelled)
+	}
+	options[f.option] = on
+	return nil
+}
+
+// splitWords splits a line into words the way a shell would, honoring
+// single and double quotes and backslash escapes
+func splitWords(line string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		current.WriteRune('\\')
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// Options contains options for the batch command
+type Options struct {
+	CollectionPath string
+	Input          io.Reader
+}
+
+// Execute runs every command from the input against one engine and returns
+// a single result covering all of them.
+//
+// References are resolved against the collection as it was before the batch
+// started, so `complete 1` followed by `complete 2` means the todos that were
+// 1 and 2, however the IDs shift in between. The commands run on a copy of
+// the data file, which replaces it once they all succeeded: if any command
+// fails, or the file was changed by someone else meanwhile, nothing is
+// applied.
+func Execute(opts Options) (*too.ChangeResult, error) {
+	commands, err := Parse(opts.Input)
+	if err != nil {
+		return nil, err
+	}
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("no commands to run")
+	}
+
+	staged, err := store.Stage(opts.CollectionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy the collection: %w", err)
+	}
+	defer func() {
+		if err := staged.Discard(); err != nil {
+			log.Debug().Err(err).Msg("failed to remove the batch's copy of the collection")
+		}
+	}()
+
+	engine, err := too.NewNanoEngine(staged.Path())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := run(engine, commands)
+	closeErr := engine.Close()
+	if err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close store: %w", closeErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := staged.Commit(); err != nil {
+		return nil, fmt.Errorf("nothing was applied: %w", err)
+	}
+	return result, nil
+}
+
+// run resolves every reference up front, then applies the commands in order
+func run(engine *too.NanoEngine, commands []Command) (*too.ChangeResult, error) {
+	for i := range commands {
+		if err := resolveRefs(engine, &commands[i]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", commands[i].Line, err)
+		}
+	}
+
+	var affectedUIDs []string
+	var removed []*models.Todo
+	seen := make(map[string]bool)
+
+	for _, command := range commands {
+		result, err := too.ExecuteUnifiedCommandWithEngine(engine, command.Name, command.Args, command.Options)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", command.Line, command.Name, err)
+		}
+
+		_, name, _ := too.LookupUnifiedCommand(command.Name)
+		for _, todo := range result.AffectedTodos {
+			if seen[todo.UID] {
+				continue
+			}
+			seen[todo.UID] = true
+			if name == "clean" {
+				removed = append(removed, todo)
+			} else {
+				affectedUIDs = append(affectedUIDs, todo.UID)
+			}
+		}
+	}
+
+	// Show the final state, as a plain list would
+	final, err := too.ExecuteUnifiedCommandWithEngine(engine, "list", nil, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	all, err := engine.GetTodos(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	byUID := make(map[string]*models.Todo, len(all))
+	for _, todo := range all {
+		byUID[todo.UID] = todo
+	}
+	var affected []*models.Todo
+	for _, uid := range affectedUIDs {
+		if todo, ok := byUID[uid]; ok {
+			affected = append(affected, todo)
+		}
+	}
+	affected = append(affected, removed...)
+
+	log.Debug().Int("commands", len(commands)).Int("affected", len(affected)).Msg("batch applied")
+
+	message := fmt.Sprintf("Ran %d commands", len(commands))
+	if len(commands) == 1 {
+		message = "Ran 1 command"
+	}
+
+	return too.NewChangeResult("batch", message, affected, final.AllTodos, final.TotalCount, final.DoneCount), nil
+}
+
+// resolveRefs replaces the references a command takes with UUIDs, which
+// stay valid while the batch shifts position paths around
+func resolveRefs(engine *too.NanoEngine, command *Command) error {
+	cmd, name, ok := too.LookupUnifiedCommand(command.Name)
+	if !ok {
+		return fmt.Errorf("unknown command: %s", command.Name)
+	}
+
+	resolve := func(ref string) (string, error) {
+		uuid, err := engine.ResolveReference(ref)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve reference '%s': %w", ref, err)
+		}
+		return uuid, nil
+	}
+
+	var refIndexes []int
+	switch {
+	case cmd.RequiresRef && cmd.AcceptsMultiple:
+		for i := range command.Args {
+			refIndexes = append(refIndexes, i)
+		}
+	case cmd.RequiresRef && len(command.Args) > 0:
+		refIndexes = append(refIndexes, 0)
+		// The destination of a move is a reference too, empty means root
+		if cmd.Attribute == models.AttributeParent && len(command.Args) > 1 && command.Args[1] != "" {
+			refIndexes = append(refIndexes, 1)
+		}
+	}
+
+	for _, i := range refIndexes {
+		uuid, err := resolve(command.Args[i])
+		if err != nil {
+			return err
+		}
+		command.Args[i] = uuid
+	}
+
+	if parent, ok := command.Options["parent"].(string); ok && name == "add" && parent != "" {
+		uuid, err := resolve(parent)
+		if err != nil {
+			return err
+		}
+		command.Options["parent"] = uuid
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package batch_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/commands/batch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTodos(t *testing.T, texts ...string) string {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "test.json")
+	for _, text := range texts {
+		_, err := too.ExecuteUnifiedCommand("add", []string{text}, map[string]interface{}{"collectionPath": dbPath})
+		require.NoError(t, err)
+	}
+	return dbPath
+}
+
+func listAll(t *testing.T, dbPath string) *too.ChangeResult {
+	t.Helper()
+
+	result, err := too.ExecuteUnifiedCommand("list", nil, map[string]interface{}{
+		"collectionPath": dbPath,
+		"all":            true,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestParse(t *testing.T) {
+	input := strings.Join([]string{
+		"# tidy up",
+		`add --to 1 "Buy oat milk"`,
+		"c 1 2",
+		"",
+		`{"command": "move", "args": ["3", ""]}`,
+		"edit 2 Call mom tonight",
+		"list -a",
+	}, "\n")
+
+	commands, err := batch.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, commands, 5)
+
+	assert.Equal(t, "add", commands[0].Name)
+	assert.Equal(t, []string{"Buy oat milk"}, commands[0].Args)
+	assert.Equal(t, "1", commands[0].Options["parent"])
+	assert.Equal(t, 2, commands[0].Line)
+
+	assert.Equal(t, []string{"1", "2"}, commands[1].Args)
+	assert.Equal(t, []string{"3", ""}, commands[2].Args)
+	assert.Equal(t, []string{"2", "Call mom tonight"}, commands[3].Args)
+	assert.Equal(t, true, commands[4].Options["all"])
+
+	t.Run("takes the command line's flags", func(t *testing.T) {
+		command, err := batch.ParseLine("search -sa --in=notes --fuzzy milk")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"caseSensitive": true,
+			"all":           true,
+			"in":            "notes",
+			"fuzzy":         true,
+		}, command.Options)
+		assert.Equal(t, []string{"milk"}, command.Args)
+
+		command, err = batch.ParseLine("list --done=false")
+		require.NoError(t, err)
+		assert.Equal(t, false, command.Options["done"])
+
+		command, err = batch.ParseLine("add -- -1 degrees")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"-1 degrees"}, command.Args)
+	})
+
+	t.Run("rejects other flags", func(t *testing.T) {
+		for _, line := range []string{
+			"list --foo",
+			"list -x",
+			"add --parent 1 Milk",
+			"complete --all 1",
+			"add --to",
+			"nope 1",
+		} {
+			_, err := batch.ParseLine(line)
+			assert.Error(t, err, line)
+		}
+	})
+
+	t.Run("reports the failing line", func(t *testing.T) {
+		_, err := batch.Parse(strings.NewReader("list\nadd \"unterminated"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 2")
+	})
+}
+
+func TestExecute(t *testing.T) {
+	t.Run("resolves refs against the initial snapshot", func(t *testing.T) {
+		dbPath := setupTodos(t, "First", "Second", "Third")
+
+		// Completing 1 shifts the pending IDs, 2 must still mean Second
+		result, err := batch.Execute(batch.Options{
+			CollectionPath: dbPath,
+			Input:          strings.NewReader("complete 1\ncomplete 2\nadd Fourth"),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "batch", result.Command)
+		assert.Equal(t, "Ran 3 commands", result.Message)
+		require.Len(t, result.AffectedTodos, 3)
+		assert.Equal(t, "First", result.AffectedTodos[0].Text)
+		assert.Equal(t, "Second", result.AffectedTodos[1].Text)
+		assert.Equal(t, "Fourth", result.AffectedTodos[2].Text)
+
+		require.Len(t, result.AllTodos, 2)
+		assert.Equal(t, "Third", result.AllTodos[0].Text)
+		assert.Equal(t, 4, result.TotalCount)
+		assert.Equal(t, 2, result.DoneCount)
+	})
+
+	t.Run("nests under and moves to snapshot refs", func(t *testing.T) {
+		dbPath := setupTodos(t, "Groceries", "Errands")
+
+		_, err := batch.Execute(batch.Options{
+			CollectionPath: dbPath,
+			Input: strings.NewReader(strings.Join([]string{
+				`{"command": "add", "args": ["Milk"], "parent": "1"}`,
+				"complete 1",
+				"move 2 1",
+			}, "\n")),
+		})
+		require.NoError(t, err)
+
+		all := listAll(t, dbPath)
+		parents := map[string]string{}
+		uids := map[string]string{}
+		for _, todo := range all.AllTodos {
+			uids[todo.Text] = todo.UID
+			parents[todo.Text] = todo.ParentID
+		}
+		assert.Equal(t, uids["Groceries"], parents["Milk"])
+		assert.Equal(t, uids["Groceries"], parents["Errands"])
+	})
+
+	t.Run("applies nothing when a command fails", func(t *testing.T) {
+		dbPath := setupTodos(t, "First", "Second")
+		before, err := os.ReadFile(dbPath)
+		require.NoError(t, err)
+
+		_, err = batch.Execute(batch.Options{
+			CollectionPath: dbPath,
+			Input:          strings.NewReader("complete 1\nadd Third\nedit 2"),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 3")
+
+		after, err := os.ReadFile(dbPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(before), string(after))
+
+		all := listAll(t, dbPath)
+		assert.Len(t, all.AllTodos, 2)
+		assert.Equal(t, 0, all.DoneCount)
+
+		// The batch ran on a copy, which is gone
+		entries, err := os.ReadDir(filepath.Dir(dbPath))
+		require.NoError(t, err)
+		for _, entry := range entries {
+			assert.NotContains(t, entry.Name(), "staged")
+		}
+	})
+
+	t.Run("fails before applying anything on unknown refs", func(t *testing.T) {
+		dbPath := setupTodos(t, "Only")
+
+		_, err := batch.Execute(batch.Options{
+			CollectionPath: dbPath,
+			Input:          strings.NewReader("complete 1\ncomplete 7"),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "'7'")
+		assert.Equal(t, 0, listAll(t, dbPath).DoneCount)
+	})
+
+	t.Run("leaves no collection behind when a new one fails", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "new.json")
+
+		_, err := batch.Execute(batch.Options{
+			CollectionPath: dbPath,
+			Input:          strings.NewReader("add First\nfly away"),
+		})
+		require.Error(t, err)
+		_, statErr := os.Stat(dbPath)
+		assert.True(t, os.IsNotExist(statErr))
+	})
+}
@@ -78,7 +78,23 @@ func ResolveScopedPath(forceGlobal bool) (string, bool) {
 	return scopeInfo.Path, scopeInfo.IsGlobal
 }
 
-// EnsureProjectGitignore ensures .todos.json is in .gitignore for project scope
+// RecordProject adds the current directory's project store, if any, to the
+// project registry, so that it shows in listings across projects
+func RecordProject() error {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	scopeInfo, err := scope.NewResolver(false).Resolve(currentDir)
+	if err != nil {
+		return err
+	}
+	return scope.RecordProject(scope.DefaultRegistryPath(), scopeInfo)
+}
+
+// EnsureProjectGitignore ensures .todos.json is in .gitignore for project scope.
+// A sub-scope's store is ignored in a .gitignore next to it, unless the
+// repository commits its todos.
 func EnsureProjectGitignore() error {
 	currentDir, err := os.Getwd()
 	if err != nil {
@@ -90,7 +106,18 @@ func EnsureProjectGitignore() error {
 	resolver := scope.NewResolver(false)
 	scopeInfo, err := resolver.Resolve(currentDir)
 	if err != nil || scopeInfo.IsGlobal || scopeInfo.GitRoot == "" {
-		return nil // Not in a git repo or using global scope
+		return nil // Using global scope
+	}
+
+	marker := scopeInfo.Marker
+	if marker == scope.StoreFile {
+		if scope.IsShared(scopeInfo.GitRoot) {
+			return nil
+		}
+		marker = scope.ProjectMarker(scopeInfo.GitRoot)
+	}
+	if !scope.UsesGitignore(marker) {
+		return nil // Not in a git or jj project
 	}
 	
 	return scope.EnsureGitignore(scopeInfo.GitRoot)
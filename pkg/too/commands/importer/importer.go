@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/arthur-debert/too/pkg/too/interchange"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/store"
+)
+
+// Parser reads an external format into a flat list of todos whose UID and
+// ParentID fields describe the source hierarchy, along with notes about
+// entries that could not be imported
+type Parser func(r io.Reader) ([]*models.Todo, []string, error)
+
+// parsers maps format names to their parser
+var parsers = map[string]Parser{
+	"todotxt":     lossless(interchange.ParseTodoTxt),
+	"org":         lossless(interchange.ParseOrg),
+	"taskwarrior": interchange.ParseTaskwarrior,
+}
+
+// lossless adapts a parser for a format that never skips entries
+func lossless(parse func(r io.Reader) ([]*models.Todo, error)) Parser {
+	return func(r io.Reader) ([]*models.Todo, []string, error) {
+		todos, err := parse(r)
+		return todos, nil, err
+	}
+}
+
+// Formats returns the names of the importable formats
+func Formats() []string {
+	names := make([]string, 0, len(parsers))
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Options contains options for the import command
+type Options struct {
+	CollectionPath string
+	Format         string
+	Input          io.Reader
+}
+
+// Result contains the result of the import command
+type Result struct {
+	Imported  int
+	Completed int
+	Skipped   []string
+	Message   string
+}
+
+// Execute imports todos from an external format into the collection.
+// Todos are created through the store adapter so they get their IDs
+// assigned exactly as if they had been added by hand. Todos whose parents
+// form a cycle are imported at the top level, with a note.
+func Execute(opts Options) (*Result, error) {
+	parse, ok := parsers[opts.Format]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q. Available formats: %v", opts.Format, Formats())
+	}
+
+	todos, skipped, err := parse(opts.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	adapter, err := store.NewNanoStoreAdapter(opts.CollectionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	defer adapter.Close()
+
+	skipped = append(skipped, flattenCycles(todos)...)
+	result := &Result{Skipped: skipped}
+
+	// Add everything as pending first: position paths only stay valid for
+	// parent references while the parents themselves are not completed
+	var completed []string
+	var addAll func(nodes []*models.HierarchicalTodo, parentPath string) error
+	addAll = func(nodes []*models.HierarchicalTodo, parentPath string) error {
+		for _, node := range nodes {
+			var parent *string
+			if parentPath != "" {
+				parent = &parentPath
+			}
+
+			created, err := adapter.Add(node.Text, parent)
+			if err != nil {
+				return fmt.Errorf("failed to import '%s': %w", node.Text, err)
+			}
+			result.Imported++
+
+			if node.GetStatus() == models.StatusDone {
+				completed = append(completed, created.UID)
+			}
+
+			if err := addAll(node.Children, created.PositionPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := addAll(models.BuildHierarchy(todos), ""); err != nil {
+		return nil, err
+	}
+
+	for _, uid := range completed {
+		if err := adapter.CompleteByUUID(uid); err != nil {
+			return nil, fmt.Errorf("failed to complete imported todo: %w", err)
+		}
+		result.Completed++
+	}
+
+	result.Message = fmt.Sprintf("Imported %d %s from %s (%d done)",
+		result.Imported, pluralize(result.Imported, "todo", "todos"), opts.Format, result.Completed)
+
+	if len(skipped) > 0 {
+		result.Message += fmt.Sprintf("\nSkipped %d %s:", len(skipped), pluralize(len(skipped), "entry", "entries"))
+		for _, note := range skipped {
+			result.Message += "\n  - " + note
+		}
+	}
+
+	return result, nil
+}
+
+// flattenCycles moves the todos whose parents lead back to themselves to
+// the top level, which BuildHierarchy would otherwise leave out, and returns
+// a note for each. Todos that only lead into a cycle keep their parent.
+func flattenCycles(todos []*models.Todo) []string {
+	parents := make(map[string]string, len(todos))
+	for _, todo := range todos {
+		parents[todo.UID] = todo.ParentID
+	}
+
+	var inCycle []*models.Todo
+	for _, todo := range todos {
+		seen := map[string]bool{todo.UID: true}
+		for current := parents[todo.UID]; current != ""; current = parents[current] {
+			if current == todo.UID {
+				inCycle = append(inCycle, todo)
+				break
+			}
+			if seen[current] {
+				break
+			}
+			seen[current] = true
+		}
+	}
+
+	var notes []string
+	for _, todo := range inCycle {
+		todo.ParentID = ""
+		notes = append(notes, fmt.Sprintf("'%s' is its own ancestor, imported at the top level", todo.Text))
+	}
+	return notes
+}
+
+func pluralize(count int, singular, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,151 @@
+package importer_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/commands/importer"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportTodoTxt(t *testing.T) {
+	t.Run("imports nested todos and completion", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "test.json")
+		input := strings.Join([]string{
+			"Groceries +home id:g",
+			"x 2025-03-14 Buy milk id:m parent:g",
+			"Buy eggs id:e parent:g",
+			"(A) Call mom @phone",
+		}, "\n")
+
+		result, err := importer.Execute(importer.Options{
+			CollectionPath: dbPath,
+			Format:         "todotxt",
+			Input:          strings.NewReader(input),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.Imported)
+		assert.Equal(t, 1, result.Completed)
+		assert.Contains(t, result.Message, "Imported 4 todos")
+
+		adapter, err := store.NewNanoStoreAdapter(dbPath)
+		require.NoError(t, err)
+		defer adapter.Close()
+
+		todos, err := adapter.List(true)
+		require.NoError(t, err)
+		roots := models.BuildHierarchy(todos)
+		require.Len(t, roots, 2)
+
+		assert.Equal(t, "Groceries +home", roots[0].Text)
+		require.Len(t, roots[0].Children, 2)
+		assert.Equal(t, "Buy milk", roots[0].Children[0].Text)
+		assert.Equal(t, models.StatusDone, roots[0].Children[0].GetStatus())
+		assert.Equal(t, "Buy eggs", roots[0].Children[1].Text)
+		assert.Equal(t, "(A) Call mom @phone", roots[1].Text)
+	})
+
+	t.Run("imports org headings", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "test.json")
+		input := "* TODO Release\n** DONE Write notes\n** TODO Tag version\n"
+
+		result, err := importer.Execute(importer.Options{
+			CollectionPath: dbPath,
+			Format:         "org",
+			Input:          strings.NewReader(input),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Imported)
+		assert.Equal(t, 1, result.Completed)
+
+		adapter, err := store.NewNanoStoreAdapter(dbPath)
+		require.NoError(t, err)
+		defer adapter.Close()
+
+		todos, err := adapter.List(true)
+		require.NoError(t, err)
+		roots := models.BuildHierarchy(todos)
+		require.Len(t, roots, 1)
+		assert.Len(t, roots[0].Children, 2)
+	})
+
+	t.Run("imports taskwarrior and reports skipped entries", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "test.json")
+		input := `[
+			{"uuid": "a", "description": "Plant tomatoes", "status": "pending", "project": "garden"},
+			{"uuid": "b", "description": "Buy seeds", "status": "completed", "project": "garden"},
+			{"uuid": "c", "description": "Water plants", "status": "recurring"}
+		]`
+
+		result, err := importer.Execute(importer.Options{
+			CollectionPath: dbPath,
+			Format:         "taskwarrior",
+			Input:          strings.NewReader(input),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Imported)
+		assert.Equal(t, 1, result.Completed)
+		require.Len(t, result.Skipped, 1)
+		assert.Contains(t, result.Message, "Skipped 1 entry")
+		assert.Contains(t, result.Message, "Water plants")
+
+		adapter, err := store.NewNanoStoreAdapter(dbPath)
+		require.NoError(t, err)
+		defer adapter.Close()
+
+		todos, err := adapter.List(true)
+		require.NoError(t, err)
+		roots := models.BuildHierarchy(todos)
+		require.Len(t, roots, 1)
+		assert.Equal(t, "garden", roots[0].Text)
+		assert.Len(t, roots[0].Children, 2)
+	})
+
+	t.Run("imports todos in parent cycles at the top level", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "test.json")
+		input := strings.Join([]string{
+			"Chicken id:c parent:e",
+			"Egg id:e parent:c",
+			"Feathers id:f parent:c",
+			"Ouroboros id:o parent:o",
+		}, "\n")
+
+		result, err := importer.Execute(importer.Options{
+			CollectionPath: dbPath,
+			Format:         "todotxt",
+			Input:          strings.NewReader(input),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.Imported)
+		require.Len(t, result.Skipped, 3)
+		assert.Contains(t, result.Message, "'Egg' is its own ancestor")
+
+		adapter, err := store.NewNanoStoreAdapter(dbPath)
+		require.NoError(t, err)
+		defer adapter.Close()
+
+		todos, err := adapter.List(true)
+		require.NoError(t, err)
+		roots := models.BuildHierarchy(todos)
+		require.Len(t, roots, 3)
+		assert.Equal(t, "Chicken", roots[0].Text)
+		require.Len(t, roots[0].Children, 1)
+		assert.Equal(t, "Feathers", roots[0].Children[0].Text)
+		assert.Equal(t, "Egg", roots[1].Text)
+		assert.Equal(t, "Ouroboros", roots[2].Text)
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		_, err := importer.Execute(importer.Options{
+			CollectionPath: filepath.Join(t.TempDir(), "test.json"),
+			Format:         "nope",
+			Input:          strings.NewReader(""),
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown import format")
+	})
+}
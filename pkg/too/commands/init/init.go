@@ -5,13 +5,17 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/scope"
 	"github.com/arthur-debert/too/pkg/too/store"
 )
 
 // Options contains options for the init command
 type Options struct {
-	DBPath     string
-	UseHomeDir bool // Create .todos file in home directory instead of current directory
+	DBPath             string
+	UseHomeDir         bool // Create .todos file in home directory instead of current directory
+	Shared             bool // Commit the collection to git instead of ignoring it
+	InstallMergeDriver bool // Register "too merge-driver" with the git repository
 }
 
 // Result contains the result of the init command
@@ -46,24 +50,52 @@ func Execute(opts Options) (*Result, error) {
 		exists = true
 	}
 
+	// Remember that the repository's todos are committed before writing the
+	// store, so that it is written in the shared layout
+	if opts.Shared {
+		root, err := scope.SetShared(filepath.Dir(storePath))
+		if err != nil {
+			return nil, too.NewValidationError("--shared needs a git repository: %v", err)
+		}
+		if err := scope.EnsureGitignore(root); err != nil {
+			return nil, fmt.Errorf("failed to update .gitignore: %w", err)
+		}
+	}
+
+	if opts.InstallMergeDriver {
+		if _, err := scope.InstallMergeDriver(filepath.Dir(storePath)); err != nil {
+			return nil, too.NewValidationError("--install-merge-driver needs a git repository: %v", err)
+		}
+	}
+
 	// Create the nanostore database
 	adapter, err := store.NewNanoStoreAdapter(storePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create store: %w", err)
 	}
-	defer adapter.Close()
+	if err := adapter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write store: %w", err)
+	}
 
+	kind := "too collection"
+	if opts.Shared {
+		kind = "shared too collection"
+	}
+	driver := ""
+	if opts.InstallMergeDriver {
+		driver = ", with the merge driver for .todos.json"
+	}
 	if !exists {
 		return &Result{
 			DBPath:  storePath,
 			Created: true,
-			Message: fmt.Sprintf("Initialized empty too collection in %s", storePath),
+			Message: fmt.Sprintf("Initialized empty %s in %s%s", kind, storePath, driver),
 		}, nil
 	}
 
 	return &Result{
 		DBPath:  storePath,
 		Created: false,
-		Message: fmt.Sprintf("Reinitialized existing too collection in %s", storePath),
+		Message: fmt.Sprintf("Reinitialized existing %s in %s%s", kind, storePath, driver),
 	}, nil
 }
@@ -0,0 +1,77 @@
+package projects
+
+import (
+	"os"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/scope"
+)
+
+// Options contains options for the projects command
+type Options struct {
+	RegistryPath   string // Defaults to scope.DefaultRegistryPath()
+	CollectionPath string // The store in use, marked as current
+}
+
+// Project is a registered project with its todo counts
+type Project struct {
+	Name    string `json:"name"`
+	Root    string `json:"root"`
+	Path    string `json:"path"`
+	Pending int    `json:"pending"`
+	Done    int    `json:"done"`
+	Current bool   `json:"current"`
+}
+
+// ProjectsResult contains the result of the projects command
+type ProjectsResult struct {
+	Projects []*Project `json:"projects"`
+}
+
+// Execute lists the registered projects with their counts. Projects whose
+// store is gone are dropped from the registry.
+func Execute(opts Options) (*ProjectsResult, error) {
+	registryPath := opts.RegistryPath
+	if registryPath == "" {
+		registryPath = scope.DefaultRegistryPath()
+	}
+	registry, err := scope.LoadRegistry(registryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProjectsResult{Projects: []*Project{}}
+	pruned := false
+	for _, registered := range append([]*scope.Project(nil), registry.Projects...) {
+		if _, err := os.Stat(registered.Path); os.IsNotExist(err) {
+			registry.Remove(registered.Path)
+			pruned = true
+			continue
+		}
+
+		engine, err := too.NewNanoEngine(registered.Path)
+		if err != nil {
+			return nil, err
+		}
+		total, done := engine.GetStats()
+		if err := engine.Close(); err != nil {
+			return nil, err
+		}
+
+		result.Projects = append(result.Projects, &Project{
+			Name:    registered.Name,
+			Root:    registered.Root,
+			Path:    registered.Path,
+			Pending: total - done,
+			Done:    done,
+			Current: registered.Path == opts.CollectionPath,
+		})
+	}
+
+	if pruned {
+		if err := registry.Save(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package projects_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arthur-debert/too/pkg/too/commands/projects"
+	"github.com/arthur-debert/too/pkg/too/scope"
+	"github.com/arthur-debert/too/pkg/too/testutil"
+)
+
+func TestProjectsCommand(t *testing.T) {
+	t.Run("lists projects with their counts", func(t *testing.T) {
+		api, apiPath := testutil.CreateStoreWithSpecs(t,
+			testutil.TodoSpec{Text: "Fix login"},
+			testutil.TodoSpec{Text: "Rotate keys", Complete: true},
+			testutil.TodoSpec{Text: "Write test"},
+		)
+		require.NoError(t, api.Close())
+		web, webPath := testutil.CreateStoreWithSpecs(t, testutil.TodoSpec{Text: "New homepage"})
+		require.NoError(t, web.Close())
+
+		registryPath := filepath.Join(t.TempDir(), "projects.json")
+		registry, err := scope.LoadRegistry(registryPath)
+		require.NoError(t, err)
+		registry.Add("/src/web", webPath)
+		registry.Add("/src/api", apiPath)
+		registry.Add("/src/gone", "/src/gone/.todos.json")
+		require.NoError(t, registry.Save())
+
+		result, err := projects.Execute(projects.Options{RegistryPath: registryPath, CollectionPath: webPath})
+		require.NoError(t, err)
+		require.Len(t, result.Projects, 2)
+
+		assert.Equal(t, "api", result.Projects[0].Name)
+		assert.Equal(t, 2, result.Projects[0].Pending)
+		assert.Equal(t, 1, result.Projects[0].Done)
+		assert.False(t, result.Projects[0].Current)
+		assert.Equal(t, "web", result.Projects[1].Name)
+		assert.True(t, result.Projects[1].Current)
+
+		// The missing store was dropped
+		registry, err = scope.LoadRegistry(registryPath)
+		require.NoError(t, err)
+		assert.Len(t, registry.Projects, 2)
+	})
+
+	t.Run("an empty registry has no projects", func(t *testing.T) {
+		result, err := projects.Execute(projects.Options{RegistryPath: filepath.Join(t.TempDir(), "projects.json")})
+		require.NoError(t, err)
+		assert.Empty(t, result.Projects)
+	})
+}
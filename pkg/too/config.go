@@ -3,6 +3,8 @@ package too
 // Config holds the configuration for too
 type Config struct {
 	Display DisplayConfig
+	// Branches tags new todos with the current git branch and hides the todos of other branches
+	Branches bool
 }
 
 // DisplayConfig holds display-related configuration
@@ -15,6 +17,10 @@ type DisplayConfig struct {
 	ShowListSummary bool
 	// UseContextualChangeView controls whether to show contextual view for changes (only affected item with context)
 	UseContextualChangeView bool
+	// ShowShortIDs controls whether todos are shown with their short ID (@a3f9)
+	ShowShortIDs bool
+	// ShowGlobalTodos controls whether lists in a project also show the global todos, under their own heading
+	ShowGlobalTodos bool
 }
 
 // DefaultConfig returns the default configuration
@@ -15,6 +15,18 @@ import (
 type NanoEngine struct {
 	adapter *store.NanoStoreAdapter
 	logger  zerolog.Logger
+
+	// The last view, see UseLastView
+	viewEnabled bool
+	view        *lastView
+	viewChanged bool
+	viewWarned  bool
+	warnings    []string
+
+	// With branchOnly, todos of other branches than branch are left out of
+	// numbering and references, see branchMode
+	branch     string
+	branchOnly bool
 }
 
 // NewNanoEngine creates a new engine instance
@@ -23,7 +35,7 @@ func NewNanoEngine(dataPath string) (*NanoEngine, error) {
 
 	adapter, err := store.NewNanoStoreAdapter(dataPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create nanostore adapter: %w", err)
+		return nil, fmt.Errorf("failed to create nanostore adapter: %w", storeError(err))
 	}
 
 	return &NanoEngine{
@@ -44,7 +56,7 @@ func (e *NanoEngine) Close() error {
 func (e *NanoEngine) Add(text string, parentID *string) (*models.Todo, error) {
 	todo, err := e.adapter.Add(text, parentID)
 	if err != nil {
-		return nil, err
+		return nil, storeError(err)
 	}
 
 	e.logger.Debug().
@@ -215,14 +227,83 @@ func (e *NanoEngine) Search(query string, showAll bool) ([]*models.Todo, error)
 }
 
 // ResolveReference converts a user-facing ID to UUID
-// Supports both position paths (1, 1.2, etc) and free text search
+// Supports position paths (1, 1.2, etc), short IDs (@a3f9), symbolic
+// references (last, 2^, next:1, see symbols.go) and free text search
 func (e *NanoEngine) ResolveReference(ref string) (string, error) {
+	return e.resolveReference(ref, true)
+}
+
+// resolveTarget converts the reference to a todo other todos are placed
+// under, a parent or a move destination, to UUID. Free text isn't searched:
+// text that happens to match a todo mustn't decide where todos go.
+func (e *NanoEngine) resolveTarget(ref string) (string, error) {
+	return e.resolveReference(ref, false)
+}
+
+func (e *NanoEngine) resolveReference(ref string, freeText bool) (string, error) {
+	uuid, err := e.lookupReference(ref, freeText)
+	if err != nil || !e.branchOnly {
+		return uuid, err
+	}
+
+	// Todos of other branches can't be named, not even by short ID
+	shown, err := e.shownOnBranch()
+	if err != nil {
+		return "", err
+	}
+	if !shown[uuid] {
+		return "", &Error{
+			Kind:    ErrNotFound,
+			Message: fmt.Sprintf("todo '%s' belongs to another branch", ref),
+			Ref:     ref,
+		}
+	}
+	return uuid, nil
+}
+
+func (e *NanoEngine) lookupReference(ref string, freeText bool) (string, error) {
+	if strings.TrimSpace(ref) == "" {
+		return "", NewInvalidRefError(ref, "reference is empty")
+	}
+	if uuid, ok, err := e.resolveSymbol(ref, freeText); ok {
+		return uuid, err
+	}
+	if isShortIDRef(ref) {
+		uuid, err := e.resolveShortID(ref)
+		if KindOf(err) != ErrNotFound || !freeText {
+			return uuid, err
+		}
+		// Words like "@cafe" look like short IDs too
+		e.logger.Debug().Str("ref", ref).Msg("no todo with this short ID, trying free text search")
+		return e.resolveFreeText(ref)
+	}
+	if reason := malformedPositionPath(ref); reason != "" {
+		return "", NewInvalidRefError(ref, reason)
+	}
+
+	// IDs from the last listing mean what was displayed
+	if uuid, ok, err := e.resolveFromView(ref); ok {
+		return uuid, err
+	}
+
+	// On a branch, positions count the todos shown there only
+	if e.branchOnly && looksLikePositionPath(ref) {
+		return e.resolveOnBranch(ref)
+	}
+
 	// First try as position path
 	uuid, err := e.adapter.ResolvePositionPath(ref)
 	if err == nil {
 		return uuid, nil
 	}
 	
+	if !freeText {
+		if looksLikePositionPath(ref) {
+			return "", NewNotFoundError(ref)
+		}
+		return "", NewInvalidRefError(ref, "expected a position, short ID or symbolic reference")
+	}
+
 	// Check if the error indicates invalid position path format
 	// In that case, try free text search
 	if strings.Contains(err.Error(), "invalid position path format") {
@@ -233,7 +314,7 @@ func (e *NanoEngine) ResolveReference(ref string) (string, error) {
 	// If it looks like a position path but failed for other reasons, return the error
 	if looksLikePositionPath(ref) {
 		e.logger.Debug().Str("ref", ref).Err(err).Msg("looks like position path, returning error")
-		return "", err
+		return "", NewNotFoundError(ref)
 	}
 	
 	// Otherwise, try free text search
@@ -285,7 +366,7 @@ func (e *NanoEngine) MutateAttributeByUUID(uuid string, attr models.AttributeTyp
 	}
 
 	if err != nil {
-		return "", err
+		return "", storeError(err)
 	}
 
 	return uuid, nil
@@ -498,6 +579,25 @@ func looksLikePositionPath(ref string) bool {
 	return positionPathRegex.MatchString(ref)
 }
 
+// malformedPositionPath explains what is wrong with refs made only of digits
+// and dots that can't be position paths, like "1..2" or "0". Such refs would
+// otherwise fall back to a free text search that can't be what was meant.
+// It returns "" for anything else.
+func malformedPositionPath(ref string) string {
+	if strings.Trim(ref, "0123456789.") != "" || strings.Trim(ref, ".") == "" {
+		return ""
+	}
+	for _, part := range strings.Split(ref, ".") {
+		if part == "" {
+			return "position paths are numbers separated by single dots"
+		}
+		if strings.Trim(part, "0") == "" {
+			return "positions start at 1"
+		}
+	}
+	return ""
+}
+
 // resolveFreeText tries to find a todo by searching for the text
 func (e *NanoEngine) resolveFreeText(text string) (string, error) {
 	// Search for exact or partial matches
@@ -506,10 +606,23 @@ func (e *NanoEngine) resolveFreeText(text string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to search for '%s': %w", text, err)
 	}
+	if e.branchOnly {
+		shown, err := e.shownOnBranch()
+		if err != nil {
+			return "", err
+		}
+		var onBranch []*models.Todo
+		for _, todo := range matches {
+			if shown[todo.UID] {
+				onBranch = append(onBranch, todo)
+			}
+		}
+		matches = onBranch
+	}
 	e.logger.Debug().Int("matches", len(matches)).Msg("search results")
 	
 	if len(matches) == 0 {
-		return "", fmt.Errorf("no todo found matching '%s'", text)
+		return "", NewNotFoundError(text)
 	}
 	
 	if len(matches) > 1 {
@@ -522,17 +635,7 @@ func (e *NanoEngine) resolveFreeText(text string) (string, error) {
 		}
 		
 		// If no exact match, return error with suggestions
-		var suggestions []string
-		suggestions = append(suggestions, fmt.Sprintf("Multiple todos found matching '%s':", text))
-		for i, todo := range matches {
-			if i >= 5 { // Limit suggestions to 5
-				suggestions = append(suggestions, "  ...")
-				break
-			}
-			suggestions = append(suggestions, fmt.Sprintf("  %s: %s", todo.PositionPath, todo.Text))
-		}
-		suggestions = append(suggestions, "Please be more specific or use the position path")
-		return "", fmt.Errorf("%s", strings.Join(suggestions, "\n"))
+		return "", NewAmbiguousError(text, matches)
 	}
 	
 	// Single match found
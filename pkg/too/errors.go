@@ -0,0 +1,190 @@
+package too
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// ErrorKind classifies the failures scripts and integrations can react to
+type ErrorKind string
+
+const (
+	// ErrNotFound means a reference didn't match any todo
+	ErrNotFound ErrorKind = "not_found"
+	// ErrAmbiguous means a free-text reference matched several todos
+	ErrAmbiguous ErrorKind = "ambiguous"
+	// ErrInvalidRef means a reference is malformed and can't match anything
+	ErrInvalidRef ErrorKind = "invalid_ref"
+	// ErrStoreLocked means another process holds the store's lock
+	ErrStoreLocked ErrorKind = "store_locked"
+	// ErrValidation means the command was called with bad arguments
+	ErrValidation ErrorKind = "validation"
+)
+
+// Exit codes for each error kind. Any other failure exits with ExitFailure.
+const (
+	ExitOK          = 0
+	ExitFailure     = 1
+	ExitValidation  = 2
+	ExitNotFound    = 3
+	ExitAmbiguous   = 4
+	ExitInvalidRef  = 5
+	ExitStoreLocked = 6
+)
+
+var exitCodes = map[ErrorKind]int{
+	ErrValidation:  ExitValidation,
+	ErrNotFound:    ExitNotFound,
+	ErrAmbiguous:   ExitAmbiguous,
+	ErrInvalidRef:  ExitInvalidRef,
+	ErrStoreLocked: ExitStoreLocked,
+}
+
+// Error is a failure of a known kind. It survives wrapping with %w, so use
+// errors.As or KindOf to inspect it.
+type Error struct {
+	Kind       ErrorKind
+	Message    string
+	Ref        string         // The reference that failed to resolve, if any
+	Candidates []*models.Todo // The todos an ambiguous reference matched
+	Err        error          // The underlying cause, if any
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for this kind of error
+func (e *Error) ExitCode() int {
+	if code, ok := exitCodes[e.Kind]; ok {
+		return code
+	}
+	return ExitFailure
+}
+
+// ErrorFields returns the machine-readable fields that structured output
+// formats render next to the error message
+func (e *Error) ErrorFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"kind":     string(e.Kind),
+		"exitCode": e.ExitCode(),
+	}
+	if e.Ref != "" {
+		fields["ref"] = e.Ref
+	}
+	if e.Kind == ErrAmbiguous {
+		candidates := make([]map[string]string, 0, len(e.Candidates))
+		for _, todo := range e.Candidates {
+			candidates = append(candidates, map[string]string{
+				"id":   todo.PositionPath,
+				"uid":  todo.UID,
+				"text": todo.Text,
+			})
+		}
+		fields["candidates"] = candidates
+	}
+	return fields
+}
+
+// KindOf returns the kind of the first typed error in err's chain, or "" if
+// there is none
+func KindOf(err error) ErrorKind {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Kind
+	}
+	return ""
+}
+
+// ExitCode returns the process exit code for err
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.ExitCode()
+	}
+	return ExitFailure
+}
+
+// NewValidationError reports a command called with bad arguments
+func NewValidationError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewNotFoundError reports a reference that matched no todo
+func NewNotFoundError(ref string) error {
+	message := fmt.Sprintf("no todo found matching '%s'", ref)
+	if looksLikePositionPath(ref) {
+		message = fmt.Sprintf("no todo at position '%s'", ref)
+	} else if isShortIDRef(ref) {
+		message = fmt.Sprintf("no todo with short ID '%s'", ref)
+	}
+	return &Error{Kind: ErrNotFound, Message: message, Ref: ref}
+}
+
+// NewInvalidRefError reports a malformed reference
+func NewInvalidRefError(ref string, reason string) error {
+	return &Error{
+		Kind:    ErrInvalidRef,
+		Message: fmt.Sprintf("invalid reference '%s': %s", ref, reason),
+		Ref:     ref,
+	}
+}
+
+// maxAmbiguousSuggestions caps the candidates listed in an ambiguity message
+const maxAmbiguousSuggestions = 5
+
+// NewAmbiguousError reports a reference that matched several todos. The
+// message lists the first few, the Candidates field holds all of them.
+func NewAmbiguousError(ref string, candidates []*models.Todo) error {
+	lines := []string{fmt.Sprintf("Multiple todos found matching '%s':", ref)}
+	for i, todo := range candidates {
+		if i >= maxAmbiguousSuggestions {
+			lines = append(lines, "  ...")
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", todo.PositionPath, todo.Text))
+	}
+	lines = append(lines, "Please be more specific or use the position path")
+
+	return &Error{
+		Kind:       ErrAmbiguous,
+		Message:    strings.Join(lines, "\n"),
+		Ref:        ref,
+		Candidates: candidates,
+	}
+}
+
+// storeError marks store failures caused by another process holding the lock
+// as ErrStoreLocked and returns any other error unchanged. The store's file
+// lock fails with the context's error when waiting for it times out, and
+// with EWOULDBLOCK when the lock is refused outright.
+func storeError(err error) error {
+	locked := errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.EWOULDBLOCK) ||
+		errors.Is(err, syscall.EAGAIN)
+	if !locked {
+		return err
+	}
+	return &Error{
+		Kind:    ErrStoreLocked,
+		Message: "the todo store is locked by another process",
+		Err:     err,
+	}
+}
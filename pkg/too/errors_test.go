@@ -0,0 +1,116 @@
+package too
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedErrors(t *testing.T) {
+	adapter, dbPath := testutil.CreateStoreWithSpecs(t,
+		testutil.TodoSpec{Text: "Buy milk"},
+		testutil.TodoSpec{Text: "Buy bread"},
+	)
+	defer adapter.Close()
+
+	engine, err := NewNanoEngine(dbPath)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	tests := []struct {
+		name     string
+		ref      string
+		kind     ErrorKind
+		exitCode int
+	}{
+		{name: "missing position", ref: "9", kind: ErrNotFound, exitCode: ExitNotFound},
+		{name: "missing text", ref: "cat", kind: ErrNotFound, exitCode: ExitNotFound},
+		{name: "ambiguous text", ref: "Buy", kind: ErrAmbiguous, exitCode: ExitAmbiguous},
+		{name: "empty reference", ref: " ", kind: ErrInvalidRef, exitCode: ExitInvalidRef},
+		{name: "double dot", ref: "1..2", kind: ErrInvalidRef, exitCode: ExitInvalidRef},
+		{name: "zero position", ref: "1.0", kind: ErrInvalidRef, exitCode: ExitInvalidRef},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := engine.ResolveReference(tt.ref)
+			require.Error(t, err)
+
+			wrapped := fmt.Errorf("failed to resolve reference '%s': %w", tt.ref, err)
+			assert.Equal(t, tt.kind, KindOf(wrapped))
+			assert.Equal(t, tt.exitCode, ExitCode(wrapped))
+		})
+	}
+
+	t.Run("ambiguous errors list every candidate", func(t *testing.T) {
+		_, err := engine.ResolveReference("Buy")
+
+		var typed *Error
+		require.True(t, errors.As(err, &typed))
+		assert.Len(t, typed.Candidates, 2)
+
+		fields := typed.ErrorFields()
+		assert.Equal(t, "ambiguous", fields["kind"])
+		assert.Equal(t, "Buy", fields["ref"])
+		assert.Len(t, fields["candidates"], 2)
+	})
+
+	t.Run("validation errors from commands", func(t *testing.T) {
+		_, err := ExecuteUnifiedCommandWithEngine(engine, "move", []string{"1"}, map[string]interface{}{})
+		assert.Equal(t, ErrValidation, KindOf(err))
+		assert.Equal(t, ExitValidation, ExitCode(err))
+
+		_, err = ExecuteUnifiedCommandWithEngine(engine, "fly", nil, map[string]interface{}{})
+		assert.Equal(t, ErrValidation, KindOf(err))
+	})
+
+	t.Run("bad parents and destinations are typed", func(t *testing.T) {
+		_, err := ExecuteUnifiedCommandWithEngine(engine, "add", []string{"Eggs"}, map[string]interface{}{"parent": "9"})
+		assert.Equal(t, ErrNotFound, KindOf(err))
+
+		_, err = ExecuteUnifiedCommandWithEngine(engine, "move", []string{"1", "7.1"}, map[string]interface{}{})
+		assert.Equal(t, ErrNotFound, KindOf(err))
+	})
+
+	t.Run("parents and destinations aren't searched as text", func(t *testing.T) {
+		_, err := ExecuteUnifiedCommandWithEngine(engine, "add", []string{"Eggs"}, map[string]interface{}{"parent": "milk"})
+		assert.Equal(t, ErrInvalidRef, KindOf(err))
+
+		_, err = ExecuteUnifiedCommandWithEngine(engine, "move", []string{"2", "milk"}, map[string]interface{}{})
+		assert.Equal(t, ErrInvalidRef, KindOf(err))
+
+		todos, err := engine.List(true)
+		require.NoError(t, err)
+		assert.Len(t, todos, 2)
+		for _, todo := range todos {
+			assert.Empty(t, todo.ParentID, todo.Text)
+		}
+	})
+
+	t.Run("untyped errors exit with the generic code", func(t *testing.T) {
+		assert.Equal(t, ExitOK, ExitCode(nil))
+		assert.Equal(t, ExitFailure, ExitCode(errors.New("boom")))
+		assert.Equal(t, ErrorKind(""), KindOf(errors.New("boom")))
+	})
+
+	t.Run("lock failures from the store", func(t *testing.T) {
+		timedOut := fmt.Errorf("failed to acquire file lock: %w", context.DeadlineExceeded)
+		assert.Equal(t, ErrStoreLocked, KindOf(storeError(timedOut)))
+		assert.Equal(t, ExitStoreLocked, ExitCode(storeError(timedOut)))
+		refused := &fs.PathError{Op: "flock", Path: ".todos.json.lock", Err: syscall.EWOULDBLOCK}
+		assert.Equal(t, ErrStoreLocked, KindOf(storeError(refused)))
+
+		// Errors merely mentioning locks are other failures
+		for _, message := range []string{"disk full", "open /home/me/blocks/.todos.json: permission denied", "clock skew", "store unlocked"} {
+			assert.Equal(t, ErrorKind(""), KindOf(storeError(errors.New(message))), message)
+		}
+		assert.NoError(t, storeError(nil))
+	})
+}
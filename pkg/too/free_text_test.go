@@ -95,7 +95,7 @@ func TestFreeTextAddressing(t *testing.T) {
 			},
 			reference:   "99",
 			expectError: true, // Position 99 doesn't exist
-			errorMsg:    "could not resolve '99'",
+			errorMsg:    "no todo at position '99'",
 		},
 	}
 
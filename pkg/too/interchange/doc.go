@@ -0,0 +1,13 @@
+// Package interchange converts todos to and from the formats used by other
+// todo tools.
+//
+// Every format is translated into too's own model: a flat list of
+// models.Todo values whose UID and ParentID fields carry the identifiers
+// found in the source document. Callers rebuild the nesting with
+// models.BuildHierarchy, so all formats share the same hierarchy handling
+// regardless of how the source expresses parent links.
+//
+// Identifiers produced by a parser are only meaningful inside the parsed
+// document. Importing assigns fresh UUIDs and position paths through the
+// store, exactly as if the todos had been added by hand.
+package interchange
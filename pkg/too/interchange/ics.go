@@ -0,0 +1,119 @@
+package interchange
+
+import (
+	"strings"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// icsTimeFormat is the RFC 5545 UTC date-time form
+const icsTimeFormat = "20060102T150405Z"
+
+// icsMaxLineOctets is the folding limit for content lines (RFC 5545 3.1)
+const icsMaxLineOctets = 75
+
+// FormatICS renders todos as an RFC 5545 calendar of VTODO components.
+//
+// Each todo keeps its UUID as UID so calendar apps recognize the same item
+// across exports, and children point at their parent through RELATED-TO.
+// The calendar has no METHOD, so DTSTAMP carries the last modification time
+// as the RFC asks; now is only used for todos that were never modified.
+func FormatICS(todos []*models.Todo, now time.Time) string {
+	var lines []string
+	lines = append(lines,
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//too//too//EN",
+		"CALSCALE:GREGORIAN",
+	)
+
+	for _, todo := range models.FlattenHierarchy(models.BuildHierarchy(todos)) {
+		lines = append(lines, formatVTodo(todo, now)...)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	var sb strings.Builder
+	for _, line := range lines {
+		sb.WriteString(foldICSLine(line))
+		sb.WriteString("\r\n")
+	}
+	return sb.String()
+}
+
+// formatVTodo renders the content lines of a single VTODO component
+func formatVTodo(todo *models.Todo, now time.Time) []string {
+	stamp := now.UTC()
+	if !todo.Modified.IsZero() {
+		stamp = todo.Modified.UTC()
+	}
+
+	lines := []string{
+		"BEGIN:VTODO",
+		"UID:" + todo.UID,
+		"DTSTAMP:" + stamp.Format(icsTimeFormat),
+		"SUMMARY:" + escapeICSText(todo.Text),
+	}
+
+	if !todo.Modified.IsZero() {
+		lines = append(lines, "LAST-MODIFIED:"+stamp.Format(icsTimeFormat))
+	}
+
+	if todo.GetStatus() == models.StatusDone {
+		lines = append(lines,
+			"STATUS:COMPLETED",
+			"PERCENT-COMPLETE:100",
+			"COMPLETED:"+stamp.Format(icsTimeFormat),
+		)
+	} else {
+		lines = append(lines, "STATUS:NEEDS-ACTION")
+	}
+
+	if todo.ParentID != "" {
+		lines = append(lines, "RELATED-TO;RELTYPE=PARENT:"+todo.ParentID)
+	}
+
+	return append(lines, "END:VTODO")
+}
+
+// escapeICSText escapes a TEXT property value (RFC 5545 3.3.11)
+func escapeICSText(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(s)
+}
+
+// foldICSLine splits content lines longer than 75 octets, continuing them on
+// lines that start with a single space. Folding never splits a UTF-8 sequence.
+func foldICSLine(line string) string {
+	if len(line) <= icsMaxLineOctets {
+		return line
+	}
+
+	var sb strings.Builder
+	limit := icsMaxLineOctets
+	start := 0
+	for start < len(line) {
+		end := start + limit
+		if end >= len(line) {
+			sb.WriteString(line[start:])
+			break
+		}
+		// Back off to the start of a UTF-8 sequence
+		for end > start && line[end]&0xC0 == 0x80 {
+			end--
+		}
+		sb.WriteString(line[start:end])
+		sb.WriteString("\r\n ")
+		start = end
+		// Continuation lines lose one octet to the leading space
+		limit = icsMaxLineOctets - 1
+	}
+	return sb.String()
+}
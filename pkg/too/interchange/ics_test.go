@@ -0,0 +1,74 @@
+package interchange_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/too/interchange"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatICS(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("wraps todos in a calendar with CRLF lines", func(t *testing.T) {
+		output := interchange.FormatICS(nil, now)
+		assert.True(t, strings.HasPrefix(output, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+		assert.True(t, strings.HasSuffix(output, "END:VCALENDAR\r\n"))
+	})
+
+	t.Run("maps status, timestamps and parent links", func(t *testing.T) {
+		todos := []*models.Todo{
+			newTodo("parent-uid", "", "Release", models.StatusPending),
+			newTodo("child-uid", "parent-uid", "Tag version", models.StatusDone),
+		}
+
+		output := interchange.FormatICS(todos, now)
+		lines := strings.Split(output, "\r\n")
+
+		assert.Contains(t, lines, "UID:parent-uid")
+		assert.Contains(t, lines, "STATUS:NEEDS-ACTION")
+		assert.Contains(t, lines, "UID:child-uid")
+		assert.Contains(t, lines, "STATUS:COMPLETED")
+		assert.Contains(t, lines, "COMPLETED:20250314T100000Z")
+		assert.Contains(t, lines, "LAST-MODIFIED:20250314T100000Z")
+		assert.Contains(t, lines, "DTSTAMP:20250314T100000Z")
+		assert.Contains(t, lines, "RELATED-TO;RELTYPE=PARENT:parent-uid")
+		assert.Equal(t, 2, strings.Count(output, "BEGIN:VTODO"))
+	})
+
+	t.Run("escapes text values", func(t *testing.T) {
+		todos := []*models.Todo{
+			newTodo("a", "", "Buy milk, eggs; bread\nand a \\ backslash", models.StatusPending),
+		}
+
+		output := interchange.FormatICS(todos, now)
+		assert.Contains(t, output, `SUMMARY:Buy milk\, eggs\; bread\nand a \\ backslash`)
+	})
+
+	t.Run("folds long lines at 75 octets", func(t *testing.T) {
+		todos := []*models.Todo{
+			newTodo("a", "", strings.Repeat("word ", 40), models.StatusPending),
+		}
+
+		output := interchange.FormatICS(todos, now)
+		for _, line := range strings.Split(output, "\r\n") {
+			assert.LessOrEqual(t, len(line), 75)
+		}
+
+		unfolded := strings.ReplaceAll(output, "\r\n ", "")
+		require.Contains(t, unfolded, "SUMMARY:"+strings.Repeat("word ", 40))
+	})
+
+	t.Run("uses now for never modified todos", func(t *testing.T) {
+		todo := newTodo("a", "", "Fresh", models.StatusPending)
+		todo.Modified = time.Time{}
+
+		output := interchange.FormatICS([]*models.Todo{todo}, now)
+		assert.Contains(t, output, "DTSTAMP:20250501T120000Z")
+		assert.NotContains(t, output, "LAST-MODIFIED")
+	})
+}
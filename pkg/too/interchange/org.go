@@ -0,0 +1,174 @@
+package interchange
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// orgTimeFormat is org-mode's inactive timestamp layout
+const orgTimeFormat = "[2006-01-02 Mon 15:04]"
+
+var (
+	orgHeading  = regexp.MustCompile(`^(\*+)\s+(?:(TODO|DONE)\s+)?(.*?)\s*$`)
+	orgProperty = regexp.MustCompile(`^\s*:([A-Za-z_-]+):\s*(.*?)\s*$`)
+	orgClosed   = regexp.MustCompile(`^\s*CLOSED:\s*\[.*\]\s*$`)
+)
+
+// FormatOrg renders todos as org-mode headings.
+//
+// Nesting is expressed by heading depth, status by the TODO and DONE
+// keywords, and each heading carries a :PROPERTIES: drawer with the todo's
+// UUID and modification time. Extra lines of multi-line todos become the
+// heading body.
+func FormatOrg(todos []*models.Todo) string {
+	var sb strings.Builder
+	writeOrgHeadings(&sb, models.BuildHierarchy(todos), 1)
+	return sb.String()
+}
+
+// writeOrgHeadings writes a level of the hierarchy and recurses into children
+func writeOrgHeadings(sb *strings.Builder, todos []*models.HierarchicalTodo, depth int) {
+	indent := strings.Repeat(" ", depth+1)
+
+	for _, todo := range todos {
+		lines := strings.Split(todo.Text, "\n")
+
+		keyword := "TODO"
+		if todo.GetStatus() == models.StatusDone {
+			keyword = "DONE"
+		}
+		fmt.Fprintf(sb, "%s %s %s\n", strings.Repeat("*", depth), keyword, lines[0])
+
+		if keyword == "DONE" && !todo.Modified.IsZero() {
+			fmt.Fprintf(sb, "%sCLOSED: %s\n", indent, todo.Modified.Format(orgTimeFormat))
+		}
+
+		fmt.Fprintf(sb, "%s:PROPERTIES:\n", indent)
+		fmt.Fprintf(sb, "%s:ID:       %s\n", indent, todo.UID)
+		if !todo.Modified.IsZero() {
+			fmt.Fprintf(sb, "%s:MODIFIED: %s\n", indent, todo.Modified.Format(orgTimeFormat))
+		}
+		fmt.Fprintf(sb, "%s:END:\n", indent)
+
+		for _, line := range lines[1:] {
+			if line == "" {
+				sb.WriteString("\n")
+				continue
+			}
+			sb.WriteString(indent + line + "\n")
+		}
+
+		writeOrgHeadings(sb, todo.Children, depth+1)
+	}
+}
+
+// ParseOrg reads org-mode headings into a flat list of todos.
+//
+// Heading depth decides the parent, DONE headings are completed and every
+// other heading is pending. The :ID: property becomes the UID, headings
+// without one get a generated identifier. Body text that is not part of a
+// drawer or planning line is appended to the todo text.
+func ParseOrg(r io.Reader) ([]*models.Todo, error) {
+	var todos []*models.Todo
+
+	// stack holds the headings that can still receive children
+	type openHeading struct {
+		depth int
+		todo  *models.Todo
+	}
+	var stack []openHeading
+	var current *models.Todo
+	var body []string
+	depth := 0
+	inDrawer := false
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		// Drop trailing blank lines between a body and the next heading
+		for len(body) > 0 && body[len(body)-1] == "" {
+			body = body[:len(body)-1]
+		}
+		if len(body) > 0 {
+			current.Text += "\n" + strings.Join(body, "\n")
+		}
+		body = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+
+		if m := orgHeading.FindStringSubmatch(line); m != nil {
+			flush()
+
+			depth = len(m[1])
+			status := models.StatusPending
+			if m[2] == "DONE" {
+				status = models.StatusDone
+			}
+			current = &models.Todo{
+				UID:      fmt.Sprintf("heading-%d", lineNum),
+				Text:     m[3],
+				Statuses: map[string]string{"completion": string(status)},
+			}
+
+			// Pop headings that are not ancestors of this one. A parent's
+			// drawer always precedes its children, so its UID is final here.
+			for len(stack) > 0 && stack[len(stack)-1].depth >= depth {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) > 0 {
+				current.ParentID = stack[len(stack)-1].todo.UID
+			}
+			stack = append(stack, openHeading{depth: depth, todo: current})
+			todos = append(todos, current)
+			inDrawer = false
+			continue
+		}
+
+		if current == nil {
+			// Text before the first heading has no todo to belong to
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == ":PROPERTIES:":
+			inDrawer = true
+		case inDrawer && trimmed == ":END:":
+			inDrawer = false
+		case inDrawer:
+			if m := orgProperty.FindStringSubmatch(line); m != nil {
+				switch strings.ToUpper(m[1]) {
+				case "ID":
+					current.UID = m[2]
+				case "MODIFIED":
+					if ts, err := time.Parse(orgTimeFormat, m[2]); err == nil {
+						current.Modified = ts
+					}
+				}
+			}
+		case orgClosed.MatchString(line) && len(body) == 0:
+			// Planning line, the DONE keyword already carries the status
+		default:
+			body = append(body, strings.TrimPrefix(line, strings.Repeat(" ", depth+1)))
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read org input: %w", err)
+	}
+
+	return todos, nil
+}
@@ -0,0 +1,99 @@
+package interchange_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/interchange"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatOrg(t *testing.T) {
+	todos := []*models.Todo{
+		newTodo("root", "", "Release", models.StatusPending),
+		newTodo("child", "root", "Write notes\nmention the fix", models.StatusDone),
+	}
+
+	expected := strings.Join([]string{
+		"* TODO Release",
+		"  :PROPERTIES:",
+		"  :ID:       root",
+		"  :MODIFIED: [2025-03-14 Fri 10:00]",
+		"  :END:",
+		"** DONE Write notes",
+		"   CLOSED: [2025-03-14 Fri 10:00]",
+		"   :PROPERTIES:",
+		"   :ID:       child",
+		"   :MODIFIED: [2025-03-14 Fri 10:00]",
+		"   :END:",
+		"   mention the fix",
+		"",
+	}, "\n")
+
+	assert.Equal(t, expected, interchange.FormatOrg(todos))
+}
+
+func TestParseOrg(t *testing.T) {
+	t.Run("reads nesting, status and ids", func(t *testing.T) {
+		input := strings.Join([]string{
+			"#+TITLE: Work",
+			"* TODO Release",
+			"  :PROPERTIES:",
+			"  :ID:       root",
+			"  :END:",
+			"** DONE Write notes",
+			"   CLOSED: [2025-03-14 Fri 10:00]",
+			"   mention the fix",
+			"*** Proofread",
+			"** TODO Tag version",
+			"* Plain heading",
+		}, "\n")
+
+		todos, err := interchange.ParseOrg(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, todos, 5)
+
+		roots := models.BuildHierarchy(todos)
+		require.Len(t, roots, 2)
+		assert.Equal(t, "root", roots[0].UID)
+		assert.Equal(t, "Release", roots[0].Text)
+		require.Len(t, roots[0].Children, 2)
+
+		notes := roots[0].Children[0]
+		assert.Equal(t, "Write notes\nmention the fix", notes.Text)
+		assert.Equal(t, models.StatusDone, notes.GetStatus())
+		require.Len(t, notes.Children, 1)
+		assert.Equal(t, "Proofread", notes.Children[0].Text)
+		assert.Equal(t, models.StatusPending, notes.Children[0].GetStatus())
+
+		assert.Equal(t, "Tag version", roots[0].Children[1].Text)
+		assert.Equal(t, "Plain heading", roots[1].Text)
+	})
+
+	t.Run("tolerates skipped levels", func(t *testing.T) {
+		input := "** TODO Orphan\n* TODO Root\n*** TODO Deep\n"
+
+		todos, err := interchange.ParseOrg(strings.NewReader(input))
+		require.NoError(t, err)
+
+		roots := models.BuildHierarchy(todos)
+		require.Len(t, roots, 2)
+		require.Len(t, roots[1].Children, 1)
+		assert.Equal(t, "Deep", roots[1].Children[0].Text)
+	})
+
+	t.Run("round trip is stable", func(t *testing.T) {
+		todos := []*models.Todo{
+			newTodo("root", "", "Release", models.StatusPending),
+			newTodo("child", "root", "Write notes\n\nmention the fix", models.StatusDone),
+			newTodo("other", "", "Water plants", models.StatusPending),
+		}
+
+		first := interchange.FormatOrg(todos)
+		parsed, err := interchange.ParseOrg(strings.NewReader(first))
+		require.NoError(t, err)
+		assert.Equal(t, first, interchange.FormatOrg(parsed))
+	})
+}
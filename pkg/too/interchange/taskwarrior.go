@@ -0,0 +1,212 @@
+package interchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// taskwarriorTask is the subset of a `task export` entry that too understands
+type taskwarriorTask struct {
+	UUID        string          `json:"uuid"`
+	Description string          `json:"description"`
+	Status      string          `json:"status"`
+	Project     string          `json:"project"`
+	Tags        []string        `json:"tags"`
+	Depends     json.RawMessage `json:"depends"`
+}
+
+// dependencies returns the UUIDs this task depends on. Taskwarrior 2.6 and
+// later export an array, older versions a comma separated string.
+func (t *taskwarriorTask) dependencies() []string {
+	if len(t.Depends) == 0 {
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(t.Depends, &list); err == nil {
+		return list
+	}
+
+	var joined string
+	if err := json.Unmarshal(t.Depends, &joined); err == nil && joined != "" {
+		return strings.Split(joined, ",")
+	}
+	return nil
+}
+
+// ParseTaskwarrior reads the JSON produced by `task export` into a flat list
+// of todos, returning notes about what could not be carried over.
+//
+// Completed and deleted tasks become done todos, recurring templates are
+// skipped since their instances are exported on their own. Projects become
+// parent todos, dotted projects nesting into each other. A dependency turns
+// into nesting when it forms a tree: a task that exactly one other task
+// depends on becomes that task's child. Dependencies shared by several tasks
+// are left flat, and a cycle is cut at one of its tasks. Tags are appended as
+// hashtags.
+func ParseTaskwarrior(r io.Reader) ([]*models.Todo, []string, error) {
+	var tasks []*taskwarriorTask
+	if err := json.NewDecoder(r).Decode(&tasks); err != nil {
+		return nil, nil, fmt.Errorf("failed to read taskwarrior export: %w", err)
+	}
+
+	var skipped []string
+	byUUID := make(map[string]*taskwarriorTask)
+	var importable []*taskwarriorTask
+
+	for _, task := range tasks {
+		switch {
+		case strings.TrimSpace(task.Description) == "":
+			skipped = append(skipped, fmt.Sprintf("task %s has no description", shortTaskUUID(task.UUID)))
+		case task.Status == "recurring":
+			skipped = append(skipped, fmt.Sprintf("recurring template '%s'", task.Description))
+		case task.UUID == "":
+			skipped = append(skipped, fmt.Sprintf("task '%s' has no uuid", task.Description))
+		default:
+			byUUID[task.UUID] = task
+			importable = append(importable, task)
+		}
+	}
+
+	// Find which tasks depend on each task
+	dependents := make(map[string][]string)
+	for _, task := range importable {
+		for _, dep := range task.dependencies() {
+			dep = strings.TrimSpace(dep)
+			if _, ok := byUUID[dep]; !ok {
+				skipped = append(skipped, fmt.Sprintf("dependency of '%s' on a task missing from the export", task.Description))
+				continue
+			}
+			dependents[dep] = append(dependents[dep], task.UUID)
+		}
+	}
+
+	// A task with exactly one dependent nests under it
+	parents := make(map[string]string)
+	for _, task := range importable {
+		deps := dependents[task.UUID]
+		switch {
+		case len(deps) == 1:
+			parents[task.UUID] = deps[0]
+		case len(deps) > 1:
+			skipped = append(skipped, fmt.Sprintf("'%s' is a dependency of %d tasks, kept flat", task.Description, len(deps)))
+		}
+	}
+
+	// Break cycles so every chain ends at a root: the first task of a cycle
+	// loses its parent, tasks that only lead into a cycle keep theirs
+	for _, task := range importable {
+		seen := map[string]bool{task.UUID: true}
+		for current := parents[task.UUID]; current != ""; current = parents[current] {
+			if current == task.UUID {
+				delete(parents, task.UUID)
+				skipped = append(skipped, fmt.Sprintf("circular dependency on '%s', kept flat", task.Description))
+				break
+			}
+			if seen[current] {
+				break
+			}
+			seen[current] = true
+		}
+	}
+
+	var todos []*models.Todo
+	projects := make(map[string]*models.Todo)
+
+	// projectTodo returns the parent todo for a dotted project, creating it
+	// and its ancestors on first use
+	var projectTodo func(name string) *models.Todo
+	projectTodo = func(name string) *models.Todo {
+		if todo, ok := projects[name]; ok {
+			return todo
+		}
+
+		todo := &models.Todo{
+			UID:      "project:" + name,
+			Text:     name,
+			Statuses: map[string]string{"completion": string(models.StatusPending)},
+		}
+		if i := strings.LastIndex(name, "."); i > 0 {
+			todo.Text = name[i+1:]
+			todo.ParentID = projectTodo(name[:i]).UID
+		}
+
+		projects[name] = todo
+		todos = append(todos, todo)
+		return todo
+	}
+
+	for _, task := range importable {
+		todo := &models.Todo{
+			UID:      task.UUID,
+			Text:     taskwarriorText(task),
+			Statuses: map[string]string{"completion": string(taskwarriorStatus(task.Status))},
+		}
+
+		if parent, ok := parents[task.UUID]; ok {
+			todo.ParentID = parent
+		} else if task.Project != "" {
+			todo.ParentID = projectTodo(task.Project).UID
+		}
+
+		todos = append(todos, todo)
+	}
+
+	// Projects whose tasks are all finished are finished too
+	for _, root := range models.BuildHierarchy(todos) {
+		completeFinishedProjects(root)
+	}
+
+	return todos, skipped, nil
+}
+
+// taskwarriorStatus maps taskwarrior statuses onto too's two states
+func taskwarriorStatus(status string) models.TodoStatus {
+	switch status {
+	case "completed", "deleted":
+		return models.StatusDone
+	default:
+		return models.StatusPending
+	}
+}
+
+// taskwarriorText builds the todo text, carrying tags over as hashtags
+func taskwarriorText(task *taskwarriorTask) string {
+	text := strings.TrimSpace(task.Description)
+	for _, tag := range task.Tags {
+		text += " #" + tag
+	}
+	return text
+}
+
+// completeFinishedProjects marks generated project todos as done when every
+// todo below them is done
+func completeFinishedProjects(node *models.HierarchicalTodo) bool {
+	allDone := true
+	for _, child := range node.Children {
+		if !completeFinishedProjects(child) {
+			allDone = false
+		}
+	}
+
+	if strings.HasPrefix(node.UID, "project:") {
+		if allDone && len(node.Children) > 0 {
+			node.Statuses["completion"] = string(models.StatusDone)
+		}
+		return allDone
+	}
+
+	return allDone && node.GetStatus() == models.StatusDone
+}
+
+// shortTaskUUID shortens a taskwarrior UUID the way `task` displays it
+func shortTaskUUID(uuid string) string {
+	if len(uuid) > 8 {
+		return uuid[:8]
+	}
+	return uuid
+}
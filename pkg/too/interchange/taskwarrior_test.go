@@ -0,0 +1,143 @@
+package interchange_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/interchange"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskwarrior(t *testing.T) {
+	t.Run("maps statuses and skips recurring templates", func(t *testing.T) {
+		input := `[
+			{"uuid": "a", "description": "Pending task", "status": "pending"},
+			{"uuid": "b", "description": "Done task", "status": "completed"},
+			{"uuid": "c", "description": "Deleted task", "status": "deleted"},
+			{"uuid": "d", "description": "Waiting task", "status": "waiting"},
+			{"uuid": "e", "description": "Weekly review", "status": "recurring"}
+		]`
+
+		todos, skipped, err := interchange.ParseTaskwarrior(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, todos, 4)
+
+		assert.Equal(t, models.StatusPending, todos[0].GetStatus())
+		assert.Equal(t, models.StatusDone, todos[1].GetStatus())
+		assert.Equal(t, models.StatusDone, todos[2].GetStatus())
+		assert.Equal(t, models.StatusPending, todos[3].GetStatus())
+
+		require.Len(t, skipped, 1)
+		assert.Contains(t, skipped[0], "Weekly review")
+	})
+
+	t.Run("projects become nested parents", func(t *testing.T) {
+		input := `[
+			{"uuid": "a", "description": "Plant tomatoes", "status": "pending", "project": "home.garden"},
+			{"uuid": "b", "description": "Fix sink", "status": "completed", "project": "home"}
+		]`
+
+		todos, _, err := interchange.ParseTaskwarrior(strings.NewReader(input))
+		require.NoError(t, err)
+
+		roots := models.BuildHierarchy(todos)
+		require.Len(t, roots, 1)
+		home := roots[0]
+		assert.Equal(t, "home", home.Text)
+		require.Len(t, home.Children, 2)
+		assert.Equal(t, "garden", home.Children[0].Text)
+		assert.Equal(t, "Plant tomatoes", home.Children[0].Children[0].Text)
+		assert.Equal(t, "Fix sink", home.Children[1].Text)
+		assert.Equal(t, models.StatusPending, home.GetStatus())
+	})
+
+	t.Run("finished projects are completed", func(t *testing.T) {
+		input := `[{"uuid": "a", "description": "Ship it", "status": "completed", "project": "launch"}]`
+
+		todos, _, err := interchange.ParseTaskwarrior(strings.NewReader(input))
+		require.NoError(t, err)
+
+		roots := models.BuildHierarchy(todos)
+		require.Len(t, roots, 1)
+		assert.Equal(t, models.StatusDone, roots[0].GetStatus())
+	})
+
+	t.Run("tree shaped dependencies become nesting", func(t *testing.T) {
+		input := `[
+			{"uuid": "release", "description": "Release", "status": "pending", "depends": ["notes", "tag"]},
+			{"uuid": "notes", "description": "Write notes", "status": "pending"},
+			{"uuid": "tag", "description": "Tag version", "status": "pending", "depends": "build"},
+			{"uuid": "build", "description": "Build", "status": "completed"}
+		]`
+
+		todos, skipped, err := interchange.ParseTaskwarrior(strings.NewReader(input))
+		require.NoError(t, err)
+		assert.Empty(t, skipped)
+
+		roots := models.BuildHierarchy(todos)
+		require.Len(t, roots, 1)
+		assert.Equal(t, "Release", roots[0].Text)
+		require.Len(t, roots[0].Children, 2)
+		assert.Equal(t, "Tag version", roots[0].Children[1].Text)
+		require.Len(t, roots[0].Children[1].Children, 1)
+		assert.Equal(t, "Build", roots[0].Children[1].Children[0].Text)
+	})
+
+	t.Run("shared and circular dependencies stay flat", func(t *testing.T) {
+		input := `[
+			{"uuid": "a", "description": "A", "status": "pending", "depends": ["shared"]},
+			{"uuid": "b", "description": "B", "status": "pending", "depends": ["shared"]},
+			{"uuid": "shared", "description": "Shared", "status": "pending"},
+			{"uuid": "x", "description": "X", "status": "pending", "depends": ["y"]},
+			{"uuid": "y", "description": "Y", "status": "pending", "depends": ["x"]}
+		]`
+
+		todos, skipped, err := interchange.ParseTaskwarrior(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, todos, 5)
+
+		for _, todo := range todos {
+			if todo.UID == "shared" {
+				assert.Empty(t, todo.ParentID)
+			}
+		}
+		roots := models.BuildHierarchy(todos)
+		assert.Len(t, roots, 4)
+		assert.Len(t, skipped, 2)
+	})
+
+	t.Run("tasks leading into a cycle keep their parent", func(t *testing.T) {
+		input := `[
+			{"uuid": "z", "description": "Z", "status": "pending"},
+			{"uuid": "x", "description": "X", "status": "pending", "depends": ["y", "z"]},
+			{"uuid": "y", "description": "Y", "status": "pending", "depends": ["x"]}
+		]`
+
+		todos, skipped, err := interchange.ParseTaskwarrior(strings.NewReader(input))
+		require.NoError(t, err)
+		assert.Len(t, skipped, 1)
+
+		parents := make(map[string]string)
+		for _, todo := range todos {
+			parents[todo.UID] = todo.ParentID
+		}
+		assert.Equal(t, "x", parents["z"])
+		assert.Equal(t, "x", parents["y"])
+		assert.Empty(t, parents["x"])
+	})
+
+	t.Run("tags become hashtags", func(t *testing.T) {
+		input := `[{"uuid": "a", "description": "Call bank", "status": "pending", "tags": ["phone", "money"]}]`
+
+		todos, _, err := interchange.ParseTaskwarrior(strings.NewReader(input))
+		require.NoError(t, err)
+		assert.Equal(t, "Call bank #phone #money", todos[0].Text)
+	})
+
+	t.Run("rejects invalid json", func(t *testing.T) {
+		_, _, err := interchange.ParseTaskwarrior(strings.NewReader("not json"))
+		assert.Error(t, err)
+	})
+}
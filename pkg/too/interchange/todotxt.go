@@ -0,0 +1,196 @@
+package interchange
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// todo.txt keys used to flatten too's hierarchy into single lines
+const (
+	todoTxtIDKey     = "id"
+	todoTxtParentKey = "parent"
+	todoTxtPriKey    = "pri"
+)
+
+var (
+	todoTxtDate     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+)
+
+// FormatTodoTxt renders todos as todo.txt lines.
+//
+// too has no dedicated fields for priorities, +project, @context or due:
+// keys, so they live in the todo text and are written back verbatim. The
+// hierarchy is flattened with id: and parent: keys that ParseTodoTxt uses to
+// rebuild the nesting. Completed todos get the "x" marker and their
+// modification date as completion date; their priority moves to a pri: key
+// as the todo.txt convention asks.
+func FormatTodoTxt(todos []*models.Todo) string {
+	var sb strings.Builder
+
+	// Walk the hierarchy so parents are always written before their children
+	for _, todo := range models.FlattenHierarchy(models.BuildHierarchy(todos)) {
+		sb.WriteString(formatTodoTxtLine(todo))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// formatTodoTxtLine renders a single todo as a todo.txt line
+func formatTodoTxtLine(todo *models.Todo) string {
+	var parts []string
+
+	// Multi-line todos are kept on one line with escaped newlines
+	text := strings.ReplaceAll(todo.Text, "\n", `\n`)
+
+	date := todo.Modified
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	if todo.GetStatus() == models.StatusDone {
+		parts = append(parts, "x", date.Format("2006-01-02"))
+
+		// Completed tasks carry their priority as a key instead of a prefix
+		priority := ""
+		if fields := strings.SplitN(text, " ", 2); len(fields) == 2 {
+			if m := todoTxtPriority.FindStringSubmatch(fields[0]); m != nil {
+				priority = m[1]
+				text = fields[1]
+			}
+		}
+		if startsWithDate(text) {
+			parts = append(parts, date.Format("2006-01-02"))
+		}
+		parts = append(parts, text)
+		if priority != "" {
+			parts = append(parts, todoTxtPriKey+":"+priority)
+		}
+	} else {
+		// A date or "x" starting the text would be read as the line's own;
+		// a creation date ahead of it keeps it text
+		if fields := strings.SplitN(text, " ", 2); len(fields) == 2 && todoTxtPriority.MatchString(fields[0]) {
+			parts = append(parts, fields[0])
+			text = fields[1]
+		} else if strings.HasPrefix(text, "x ") {
+			parts = append(parts, date.Format("2006-01-02"))
+		}
+		if startsWithDate(text) {
+			parts = append(parts, date.Format("2006-01-02"))
+		}
+		parts = append(parts, text)
+	}
+
+	parts = append(parts, todoTxtIDKey+":"+todo.UID)
+	if todo.ParentID != "" {
+		parts = append(parts, todoTxtParentKey+":"+todo.ParentID)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// startsWithDate reports whether text starts with a todo.txt date
+func startsWithDate(text string) bool {
+	fields := strings.Fields(text)
+	return len(fields) > 0 && todoTxtDate.MatchString(fields[0])
+}
+
+// ParseTodoTxt reads todo.txt lines into a flat list of todos.
+//
+// The "x" marker maps to the done status, whether or not a completion date
+// follows it.
+// Dates in their todo.txt positions are dropped, since too
+// tracks modification times itself. Priorities, +project, @context and other
+// key:value tokens such as due: stay in the text. The id: and parent: keys
+// written by FormatTodoTxt become the UID and ParentID of the returned todos;
+// lines without an id: key get a generated one.
+func ParseTodoTxt(r io.Reader) ([]*models.Todo, error) {
+	var todos []*models.Todo
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		todo := parseTodoTxtLine(line)
+		if todo.Text == "" {
+			continue
+		}
+		if todo.UID == "" {
+			todo.UID = fmt.Sprintf("line-%d", lineNum)
+		}
+		todos = append(todos, todo)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read todo.txt input: %w", err)
+	}
+
+	return todos, nil
+}
+
+// parseTodoTxtLine parses a single non-empty todo.txt line
+func parseTodoTxtLine(line string) *models.Todo {
+	fields := strings.Fields(line)
+	status := models.StatusPending
+
+	var words []string
+	if strings.HasPrefix(line, "x ") {
+		// Completion marker, optionally followed by the completion and
+		// creation dates
+		status = models.StatusDone
+		fields = fields[1:]
+		for i := 0; i < 2 && len(fields) > 0 && todoTxtDate.MatchString(fields[0]); i++ {
+			fields = fields[1:]
+		}
+	} else {
+		// Priority of pending tasks, optionally followed by a creation date
+		if len(fields) > 0 && todoTxtPriority.MatchString(fields[0]) {
+			words = append(words, fields[0])
+			fields = fields[1:]
+		}
+		if len(fields) > 0 && todoTxtDate.MatchString(fields[0]) {
+			fields = fields[1:]
+		}
+	}
+
+	todo := &models.Todo{}
+	priority := ""
+	for _, field := range fields {
+		key, value, found := strings.Cut(field, ":")
+		if found && value != "" {
+			switch key {
+			case todoTxtIDKey:
+				todo.UID = value
+				continue
+			case todoTxtParentKey:
+				todo.ParentID = value
+				continue
+			case todoTxtPriKey:
+				if status == models.StatusDone && len(value) == 1 {
+					priority = value
+					continue
+				}
+			}
+		}
+		words = append(words, field)
+	}
+
+	if priority != "" {
+		words = append([]string{"(" + priority + ")"}, words...)
+	}
+
+	todo.Text = strings.ReplaceAll(strings.Join(words, " "), `\n`, "\n")
+	todo.Statuses = map[string]string{"completion": string(status)}
+	return todo
+}
@@ -0,0 +1,180 @@
+package interchange_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/too/interchange"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTodo(uid, parentID, text string, status models.TodoStatus) *models.Todo {
+	return &models.Todo{
+		UID:      uid,
+		ParentID: parentID,
+		Text:     text,
+		Statuses: map[string]string{"completion": string(status)},
+		Modified: time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestFormatTodoTxt(t *testing.T) {
+	t.Run("pending todo keeps tokens in place", func(t *testing.T) {
+		todos := []*models.Todo{
+			newTodo("a", "", "(A) Call mom +family @phone due:2025-04-01", models.StatusPending),
+		}
+
+		output := interchange.FormatTodoTxt(todos)
+		assert.Equal(t, "(A) Call mom +family @phone due:2025-04-01 id:a\n", output)
+	})
+
+	t.Run("completed todo gets marker, date and pri key", func(t *testing.T) {
+		todos := []*models.Todo{
+			newTodo("a", "", "(B) File taxes", models.StatusDone),
+		}
+
+		output := interchange.FormatTodoTxt(todos)
+		assert.Equal(t, "x 2025-03-14 File taxes pri:B id:a\n", output)
+	})
+
+	t.Run("children follow their parent with a parent key", func(t *testing.T) {
+		todos := []*models.Todo{
+			newTodo("child", "root", "Buy milk", models.StatusPending),
+			newTodo("root", "", "Groceries", models.StatusPending),
+		}
+
+		lines := strings.Split(strings.TrimSpace(interchange.FormatTodoTxt(todos)), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, "Groceries id:root", lines[0])
+		assert.Equal(t, "Buy milk id:child parent:root", lines[1])
+	})
+
+	t.Run("text that reads as a marker or date gets a creation date", func(t *testing.T) {
+		todos := []*models.Todo{
+			newTodo("a", "", "2025-04-01 Dentist", models.StatusPending),
+			newTodo("b", "", "(A) 2025-04-01 Dentist", models.StatusPending),
+			newTodo("c", "", "x 2025-01-01 marks the spot", models.StatusPending),
+			newTodo("d", "", "2025-04-01 Dentist", models.StatusDone),
+		}
+
+		lines := strings.Split(strings.TrimSpace(interchange.FormatTodoTxt(todos)), "\n")
+		assert.Equal(t, []string{
+			"2025-03-14 2025-04-01 Dentist id:a",
+			"(A) 2025-03-14 2025-04-01 Dentist id:b",
+			"2025-03-14 x 2025-01-01 marks the spot id:c",
+			"x 2025-03-14 2025-03-14 2025-04-01 Dentist id:d",
+		}, lines)
+	})
+
+	t.Run("multi-line text is escaped", func(t *testing.T) {
+		todos := []*models.Todo{
+			newTodo("a", "", "First line\nSecond line", models.StatusPending),
+		}
+
+		assert.Equal(t, `First line\nSecond line id:a`+"\n", interchange.FormatTodoTxt(todos))
+	})
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	t.Run("parses completion, dates and priorities", func(t *testing.T) {
+		input := strings.Join([]string{
+			"(A) 2025-01-01 Call mom +family @phone due:2025-04-01",
+			"x 2025-03-14 2025-01-01 File taxes pri:B",
+			"",
+			"Plain task",
+		}, "\n")
+
+		todos, err := interchange.ParseTodoTxt(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, todos, 3)
+
+		assert.Equal(t, "(A) Call mom +family @phone due:2025-04-01", todos[0].Text)
+		assert.Equal(t, models.StatusPending, todos[0].GetStatus())
+
+		assert.Equal(t, "(B) File taxes", todos[1].Text)
+		assert.Equal(t, models.StatusDone, todos[1].GetStatus())
+
+		assert.Equal(t, "Plain task", todos[2].Text)
+		assert.Equal(t, "line-4", todos[2].UID)
+	})
+
+	t.Run("a lowercase x and a space mark completion", func(t *testing.T) {
+		input := strings.Join([]string{
+			"x Download Todo.txt mobile app @Phone",
+			"x 2025-03-14 Done",
+			"X 2025-03-14 Shout",
+			"xylophone lesson",
+		}, "\n")
+
+		todos, err := interchange.ParseTodoTxt(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, todos, 4)
+
+		assert.Equal(t, "Download Todo.txt mobile app @Phone", todos[0].Text)
+		assert.Equal(t, models.StatusDone, todos[0].GetStatus())
+		assert.Equal(t, "Done", todos[1].Text)
+		assert.Equal(t, models.StatusDone, todos[1].GetStatus())
+		assert.Equal(t, "X 2025-03-14 Shout", todos[2].Text)
+		assert.Equal(t, models.StatusPending, todos[2].GetStatus())
+		assert.Equal(t, "xylophone lesson", todos[3].Text)
+		assert.Equal(t, models.StatusPending, todos[3].GetStatus())
+	})
+
+	t.Run("drops only the dates in their positions", func(t *testing.T) {
+		input := strings.Join([]string{
+			"2025-01-01 2025-04-01 Dentist",
+			"(A) 2025-01-01 2025-04-01 Dentist",
+			"x 2025-03-14 2025-01-01 2025-04-01 Dentist",
+			"Dentist on 2025-04-01",
+		}, "\n")
+
+		todos, err := interchange.ParseTodoTxt(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, todos, 4)
+
+		assert.Equal(t, "2025-04-01 Dentist", todos[0].Text)
+		assert.Equal(t, "(A) 2025-04-01 Dentist", todos[1].Text)
+		assert.Equal(t, "2025-04-01 Dentist", todos[2].Text)
+		assert.Equal(t, models.StatusDone, todos[2].GetStatus())
+		assert.Equal(t, "Dentist on 2025-04-01", todos[3].Text)
+	})
+
+	t.Run("rebuilds nesting from parent keys", func(t *testing.T) {
+		input := "Groceries id:root\nBuy milk id:child parent:root\n"
+
+		todos, err := interchange.ParseTodoTxt(strings.NewReader(input))
+		require.NoError(t, err)
+
+		roots := models.BuildHierarchy(todos)
+		require.Len(t, roots, 1)
+		assert.Equal(t, "Groceries", roots[0].Text)
+		require.Len(t, roots[0].Children, 1)
+		assert.Equal(t, "Buy milk", roots[0].Children[0].Text)
+	})
+
+	t.Run("round trip is stable", func(t *testing.T) {
+		todos := []*models.Todo{
+			newTodo("root", "", "(A) Project +work", models.StatusPending),
+			newTodo("child", "root", "(C) Write spec\nwith details", models.StatusDone),
+			newTodo("other", "", "Water plants @home", models.StatusPending),
+			newTodo("dated", "", "2025-04-01 Dentist", models.StatusPending),
+			newTodo("marked", "", "x ray the package", models.StatusPending),
+		}
+
+		first := interchange.FormatTodoTxt(todos)
+		parsed, err := interchange.ParseTodoTxt(strings.NewReader(first))
+		require.NoError(t, err)
+		require.Len(t, parsed, 5)
+
+		for i, todo := range parsed {
+			todo.Modified = todos[0].Modified
+			original := models.FlattenHierarchy(models.BuildHierarchy(todos))[i]
+			assert.Equal(t, original.Text, todo.Text)
+			assert.Equal(t, original.GetStatus(), todo.GetStatus())
+		}
+		assert.Equal(t, first, interchange.FormatTodoTxt(parsed))
+	})
+}
@@ -36,7 +36,10 @@ type Todo struct {
 	UID          string            `json:"uid"`       // Stable unique identifier
 	ParentID     string            `json:"parentId"`  // Parent UID, empty for root items
 	Text         string            `json:"text"`      // Todo content
+	Notes        string            `json:"notes,omitempty"` // Free-form description kept in the document body
 	PositionPath string            `json:"-"`         // User-facing ID like "1", "1.2", "c1"
+	ShortID      string            `json:"-"`         // Shortest unambiguous UID prefix, referenced as "@a3f9"
+	Branch       string            `json:"branch,omitempty"` // Git branch the todo belongs to, empty for all branches
 	Statuses     map[string]string `json:"statuses"`  // Status dimensions
 	Modified     time.Time         `json:"modified"`  // Last modification timestamp
 }
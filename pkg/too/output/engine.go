@@ -34,6 +34,8 @@ func templateFuncs() template.FuncMap {
 	funcs["buildHierarchy"] = models.BuildHierarchy
 	funcs["countHierarchy"] = countHierarchy
 	funcs["buildContextualView"] = buildContextualView
+	funcs["markLines"] = markLines
+	funcs["shortIDTag"] = shortIDTag
 	funcs["getConfig"] = func() *too.Config {
 		return too.GetConfig()
 	}
@@ -68,6 +70,19 @@ func NewEngine() (*Engine, error) {
 	lipbalmEngine.Config().Callbacks = lipbalm.RenderCallbacks{
 		// Pre-process callback to handle template selection
 		PreProcess: func(format string, data interface{}) interface{} {
+			// Machine readable formats get the versioned result schema
+			if cr, ok := data.(*too.ChangeResult); ok && (format == "json" || format == "yaml") {
+				return NewSchemaResult(cr)
+			}
+			if er, ok := data.(*too.EverywhereResult); ok && (format == "json" || format == "yaml") {
+				return NewSchemaEverywhereResult(er)
+			}
+
+			// Search results show the matches highlighted among their ancestors
+			if cr, ok := data.(*too.ChangeResult); ok && format == "term" && cr.Command == "search" {
+				return NewSearchResults(cr)
+			}
+
 			// Check if it's a ChangeResult and we should use contextual view
 			if cr, ok := data.(*too.ChangeResult); ok && format == "term" {
 				config := too.GetConfig()
@@ -131,3 +146,10 @@ func countHierarchy(todos []*models.HierarchicalTodo) map[string]int {
 	return counts
 }
 
+// shortIDTag renders a todo's short ID after its text when they are shown
+func shortIDTag(shortID string) string {
+	if shortID == "" || !too.GetConfig().Display.ShowShortIDs {
+		return ""
+	}
+	return " <subdued>" + too.ShortIDPrefix + shortID + "</subdued>"
+}
@@ -39,7 +39,8 @@ func TestEngine_ChangeResult(t *testing.T) {
 		require.NoError(t, err)
 
 		output := buf.String()
-		assert.Contains(t, output, `"Command"`)
+		assert.Contains(t, output, `"command": "add"`)
+		assert.Contains(t, output, `"schemaVersion": 1`)
 		assert.Contains(t, output, `"test-123"`)
 		assert.Contains(t, output, `"Test todo"`)
 	})
@@ -0,0 +1,31 @@
+package output
+
+import (
+	"github.com/arthur-debert/too/pkg/lipbalm"
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// Register too's interchange formatters so they are listed by `too formats`
+// and accepted by --format like the built-in ones
+func init() {
+	lipbalm.RegisterFormatter(&TodoTxtFormatter{})
+	lipbalm.RegisterFormatter(&ICSFormatter{})
+	lipbalm.RegisterFormatter(&OrgFormatter{})
+	lipbalm.RegisterFormatter(&HTMLFormatter{})
+}
+
+// exportableTodos extracts the todos an interchange formatter should write
+// from the data handed to the render engine
+func exportableTodos(data interface{}) ([]*models.Todo, bool) {
+	switch v := data.(type) {
+	case *too.ChangeResult:
+		return v.AllTodos, true
+	case *ChangeResultContextual:
+		return v.AllTodos, true
+	case []*models.Todo:
+		return v, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,138 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/lipbalm"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+//go:embed templates/report.html
+var reportTemplateSource string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportTemplateSource))
+
+// HTMLFormatter renders todos as a self-contained HTML report page
+type HTMLFormatter struct{}
+
+func (f *HTMLFormatter) Name() string        { return "html" }
+func (f *HTMLFormatter) Description() string { return "Self-contained HTML report page" }
+
+func (f *HTMLFormatter) Render(data interface{}, config *lipbalm.Config) (string, error) {
+	todos, ok := exportableTodos(data)
+	if !ok {
+		// Not a todo collection, fall back to simple rendering
+		return fmt.Sprintf("%+v", data), nil
+	}
+
+	var buf strings.Builder
+	if err := reportTemplate.Execute(&buf, newHTMLReport(todos)); err != nil {
+		return "", fmt.Errorf("failed to render html report: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// htmlColor is a CSS custom property taken from the terminal palette
+type htmlColor struct {
+	Name  string
+	Light string
+	Dark  string
+}
+
+// htmlPalette maps the colors in styles.go to the CSS variables used by the
+// report, so the page looks like the terminal output
+func htmlPalette() []htmlColor {
+	colors := []struct {
+		name  string
+		color lipgloss.AdaptiveColor
+	}{
+		{"primary", PRIMARY_TEXT},
+		{"subdued", SUBDUED_TEXT},
+		{"muted", MUTED_TEXT},
+		{"faint", FAINT_TEXT},
+		{"very-faint", VERY_FAINT_TEXT},
+		{"success", SUCCESS_COLOR},
+		{"error", ERROR_COLOR},
+		{"warning", WARNING_COLOR},
+		{"info", INFO_COLOR},
+		{"accent", ACCENT_COLOR},
+	}
+
+	palette := make([]htmlColor, 0, len(colors))
+	for _, c := range colors {
+		palette = append(palette, htmlColor{Name: c.name, Light: c.color.Light, Dark: c.color.Dark})
+	}
+	return palette
+}
+
+// htmlReport is the data handed to the report template
+type htmlReport struct {
+	Title   string
+	Palette []htmlColor
+	Roots   []*htmlNode
+	Done    int
+	Total   int
+}
+
+// htmlNode is one todo in the report, with progress counted over all of
+// its descendants
+type htmlNode struct {
+	Position string
+	Text     string
+	Status   string
+	Symbol   string
+	Children []*htmlNode
+	Done     int
+	Total    int
+	Percent  int
+}
+
+func newHTMLReport(todos []*models.Todo) *htmlReport {
+	report := &htmlReport{
+		Title:   "Todos",
+		Palette: htmlPalette(),
+	}
+
+	for _, root := range models.BuildHierarchy(todos) {
+		node := newHTMLNode(root)
+		report.Roots = append(report.Roots, node)
+		report.Done += node.Done
+		report.Total += node.Total
+		if node.Status == "done" {
+			report.Done++
+		}
+		report.Total++
+	}
+
+	return report
+}
+
+func newHTMLNode(todo *models.HierarchicalTodo) *htmlNode {
+	node := &htmlNode{
+		Position: todo.PositionPath,
+		Text:     todo.Text,
+		Status:   todo.EffectiveStatus,
+		Symbol:   GetStatusSymbol(todo.EffectiveStatus),
+	}
+
+	for _, child := range todo.Children {
+		childNode := newHTMLNode(child)
+		node.Children = append(node.Children, childNode)
+		node.Done += childNode.Done
+		node.Total += childNode.Total
+		if childNode.Status == "done" {
+			node.Done++
+		}
+		node.Total++
+	}
+
+	if node.Total > 0 {
+		node.Percent = node.Done * 100 / node.Total
+	}
+
+	return node
+}
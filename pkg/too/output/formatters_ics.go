@@ -0,0 +1,26 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/lipbalm"
+	"github.com/arthur-debert/too/pkg/too/interchange"
+)
+
+// ICSFormatter renders todos as iCalendar VTODO entries
+type ICSFormatter struct{}
+
+func (f *ICSFormatter) Name() string { return "ics" }
+func (f *ICSFormatter) Description() string {
+	return "iCalendar (RFC 5545) todos for calendar apps"
+}
+
+func (f *ICSFormatter) Render(data interface{}, config *lipbalm.Config) (string, error) {
+	todos, ok := exportableTodos(data)
+	if !ok {
+		// Not a todo collection, fall back to simple rendering
+		return fmt.Sprintf("%+v", data), nil
+	}
+	return interchange.FormatICS(todos, time.Now()), nil
+}
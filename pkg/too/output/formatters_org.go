@@ -0,0 +1,23 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/arthur-debert/too/pkg/lipbalm"
+	"github.com/arthur-debert/too/pkg/too/interchange"
+)
+
+// OrgFormatter renders todos as org-mode headings
+type OrgFormatter struct{}
+
+func (f *OrgFormatter) Name() string        { return "org" }
+func (f *OrgFormatter) Description() string { return "Org-mode headings for Emacs" }
+
+func (f *OrgFormatter) Render(data interface{}, config *lipbalm.Config) (string, error) {
+	todos, ok := exportableTodos(data)
+	if !ok {
+		// Not a todo collection, fall back to simple rendering
+		return fmt.Sprintf("%+v", data), nil
+	}
+	return interchange.FormatOrg(todos), nil
+}
@@ -0,0 +1,77 @@
+package output_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/lipbalm"
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterchangeFormatters(t *testing.T) {
+	engine, err := output.NewEngine()
+	require.NoError(t, err)
+
+	result := &too.ChangeResult{
+		Command: "list",
+		AllTodos: []*models.Todo{
+			{UID: "p", Text: "Parent", PositionPath: "1", Statuses: map[string]string{"completion": "pending"}},
+			{UID: "c", ParentID: "p", Text: "Child", PositionPath: "1.1", Statuses: map[string]string{"completion": "pending"}},
+		},
+	}
+
+	t.Run("todotxt is registered", func(t *testing.T) {
+		assert.True(t, lipbalm.HasFormat("todotxt"))
+	})
+
+	t.Run("todotxt renders change results", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := engine.GetLipbalmEngine().Render(&buf, "todotxt", result)
+		require.NoError(t, err)
+		assert.Equal(t, "Parent id:p\nChild id:c parent:p\n", buf.String())
+	})
+
+	t.Run("ics renders change results", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := engine.GetLipbalmEngine().Render(&buf, "ics", result)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "BEGIN:VCALENDAR")
+		assert.Contains(t, buf.String(), "RELATED-TO;RELTYPE=PARENT:p")
+	})
+
+	t.Run("org renders change results", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := engine.GetLipbalmEngine().Render(&buf, "org", result)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "* TODO Parent\n")
+		assert.Contains(t, buf.String(), "** TODO Child\n")
+	})
+	t.Run("html renders a collapsible report", func(t *testing.T) {
+		report := &too.ChangeResult{
+			Command: "list",
+			AllTodos: []*models.Todo{
+				{UID: "p", Text: "Parent", PositionPath: "1", Statuses: map[string]string{"completion": "pending"}},
+				{UID: "a", ParentID: "p", Text: "Done <child>", PositionPath: "1.1", Statuses: map[string]string{"completion": "done"}},
+				{UID: "b", ParentID: "p", Text: "Open child", PositionPath: "1.2", Statuses: map[string]string{"completion": "pending"}},
+			},
+		}
+
+		var buf bytes.Buffer
+		err := engine.GetLipbalmEngine().Render(&buf, "html", report)
+		require.NoError(t, err)
+		html := buf.String()
+
+		assert.Contains(t, html, "<!DOCTYPE html>")
+		assert.Contains(t, html, "--success: #2B8A3E;")
+		assert.Contains(t, html, `<li class="mixed">`)
+		assert.Contains(t, html, "<details open>")
+		assert.Contains(t, html, `width: 50%`)
+		assert.Contains(t, html, "1/2")
+		assert.Contains(t, html, "Done &lt;child&gt;")
+		assert.Contains(t, html, "1 of 3 done")
+	})
+}
@@ -0,0 +1,25 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/arthur-debert/too/pkg/lipbalm"
+	"github.com/arthur-debert/too/pkg/too/interchange"
+)
+
+// TodoTxtFormatter renders todos as todo.txt lines
+type TodoTxtFormatter struct{}
+
+func (f *TodoTxtFormatter) Name() string { return "todotxt" }
+func (f *TodoTxtFormatter) Description() string {
+	return "todo.txt lines for todo.txt-compatible tools"
+}
+
+func (f *TodoTxtFormatter) Render(data interface{}, config *lipbalm.Config) (string, error) {
+	todos, ok := exportableTodos(data)
+	if !ok {
+		// Not a todo collection, fall back to simple rendering
+		return fmt.Sprintf("%+v", data), nil
+	}
+	return interchange.FormatTodoTxt(todos), nil
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"html"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too"
+)
+
+// markLines splits text into lines wrapped in tag. When match is a hit in
+// field, its spans are wrapped in <match> instead. The text is escaped so it
+// can't break the markup. With onlyMatching, lines without a hit are left out.
+func markLines(text string, match *too.SearchMatch, field string, tag string, onlyMatching bool) []string {
+	var spans []too.Span
+	if match != nil && match.Field == field {
+		spans = match.Spans
+	}
+
+	var lines []string
+	lineStart := 0
+	for _, line := range strings.Split(text, "\n") {
+		lineEnd := lineStart + len(line)
+
+		var b strings.Builder
+		pos := lineStart
+		matched := false
+		for _, span := range spans {
+			start, end := max(span.Start, lineStart), min(span.End, lineEnd)
+			if start >= end {
+				continue
+			}
+			matched = true
+			writeTagged(&b, tag, text[pos:start])
+			writeTagged(&b, "match", text[start:end])
+			pos = end
+		}
+		writeTagged(&b, tag, text[pos:lineEnd])
+
+		if matched || !onlyMatching {
+			lines = append(lines, b.String())
+		}
+		lineStart = lineEnd + 1
+	}
+	return lines
+}
+
+// writeTagged writes s escaped and wrapped in tag, skipping empty strings
+func writeTagged(b *strings.Builder, tag, s string) {
+	if s == "" {
+		return
+	}
+	b.WriteString("<" + tag + ">" + html.EscapeString(s) + "</" + tag + ">")
+}
@@ -0,0 +1,37 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkLines(t *testing.T) {
+	match := &too.SearchMatch{Field: too.SearchInText, Spans: []too.Span{{Start: 4, End: 8}, {Start: 13, End: 17}}}
+
+	t.Run("wraps spans in match tags", func(t *testing.T) {
+		lines := markLines("Buy milk & eggs", &too.SearchMatch{Field: too.SearchInText, Spans: []too.Span{{Start: 4, End: 8}}}, too.SearchInText, "active-todo", false)
+		assert.Equal(t, []string{
+			"<active-todo>Buy </active-todo><match>milk</match><active-todo> &amp; eggs</active-todo>",
+		}, lines)
+	})
+
+	t.Run("splits spans across lines", func(t *testing.T) {
+		lines := markLines("Buy milk\nand milk", match, too.SearchInText, "muted", false)
+		assert.Equal(t, []string{
+			"<muted>Buy </muted><match>milk</match>",
+			"<muted>and </muted><match>milk</match>",
+		}, lines)
+	})
+
+	t.Run("only matching lines", func(t *testing.T) {
+		lines := markLines("first\nsecond", &too.SearchMatch{Field: too.SearchInNotes, Spans: []too.Span{{Start: 6, End: 9}}}, too.SearchInNotes, "subdued", true)
+		assert.Equal(t, []string{"<match>sec</match><subdued>ond</subdued>"}, lines)
+	})
+
+	t.Run("ignores matches in other fields", func(t *testing.T) {
+		assert.Equal(t, []string{"<muted>Buy milk</muted>"}, markLines("Buy milk", match, too.SearchInNotes, "muted", false))
+		assert.Empty(t, markLines("Buy milk", nil, too.SearchInNotes, "subdued", true))
+	})
+}
@@ -0,0 +1,182 @@
+package output
+
+import (
+	_ "embed"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// SchemaVersion is the version of the JSON and YAML result format. It is
+// bumped whenever a field is removed or changes meaning; new fields may be
+// added without a bump.
+const SchemaVersion = 1
+
+// JSONSchema is the JSON Schema document describing SchemaResult
+//
+//go:embed schema/result.v1.schema.json
+var JSONSchema []byte
+
+// SchemaResult is what --format json and --format yaml print for a command
+type SchemaResult struct {
+	SchemaVersion int           `json:"schemaVersion" yaml:"schemaVersion"`
+	Command       string        `json:"command" yaml:"command"`
+	Message       string        `json:"message" yaml:"message"`
+	Counts        SchemaCounts  `json:"counts" yaml:"counts"`
+	Affected      []*SchemaTodo `json:"affected" yaml:"affected"`
+	Todos         []*SchemaTodo `json:"todos" yaml:"todos"`
+	Matches       []SchemaMatch `json:"matches,omitempty" yaml:"matches,omitempty"`
+	Warnings      []string      `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// SchemaCounts summarizes the collection. Total, Done and Pending cover the
+// whole collection, Shown only the todos in the result.
+type SchemaCounts struct {
+	Total   int `json:"total" yaml:"total"`
+	Done    int `json:"done" yaml:"done"`
+	Pending int `json:"pending" yaml:"pending"`
+	Shown   int `json:"shown" yaml:"shown"`
+}
+
+// SchemaTodo is a todo in the result. ID is the position path to pass back
+// to commands like `too complete`, UID the stable identifier.
+type SchemaTodo struct {
+	ID              string        `json:"id" yaml:"id"`
+	UID             string        `json:"uid" yaml:"uid"`
+	ShortID         string        `json:"shortId,omitempty" yaml:"shortId,omitempty"`
+	ParentUID       string        `json:"parentUid,omitempty" yaml:"parentUid,omitempty"`
+	Branch          string        `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Text            string        `json:"text" yaml:"text"`
+	Notes           string        `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Status          string        `json:"status" yaml:"status"`
+	EffectiveStatus string        `json:"effectiveStatus" yaml:"effectiveStatus"`
+	Modified        time.Time     `json:"modified" yaml:"modified"`
+	Children        []*SchemaTodo `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// SchemaMatch is a search hit. Todos holds the matched todos nested under
+// their ancestors, Matches lists the hits themselves, best first.
+type SchemaMatch struct {
+	ID    string     `json:"id" yaml:"id"`
+	UID   string     `json:"uid" yaml:"uid"`
+	Field string     `json:"field" yaml:"field"`
+	Score int        `json:"score" yaml:"score"`
+	Spans []too.Span `json:"spans" yaml:"spans"`
+}
+
+// NewSchemaResult converts a command result to the versioned format. Todos
+// are nested under their parents, affected todos are listed flat.
+func NewSchemaResult(result *too.ChangeResult) *SchemaResult {
+	schema := &SchemaResult{
+		SchemaVersion: SchemaVersion,
+		Command:       result.Command,
+		Message:       result.Message,
+		Counts: SchemaCounts{
+			Total:   result.TotalCount,
+			Done:    result.DoneCount,
+			Pending: result.TotalCount - result.DoneCount,
+			Shown:   len(result.AllTodos),
+		},
+		Affected: []*SchemaTodo{},
+		Todos:    []*SchemaTodo{},
+		Warnings: result.Warnings,
+	}
+
+	effective := make(map[string]string)
+	var convert func(nodes []*models.HierarchicalTodo) []*SchemaTodo
+	convert = func(nodes []*models.HierarchicalTodo) []*SchemaTodo {
+		converted := make([]*SchemaTodo, 0, len(nodes))
+		for _, node := range nodes {
+			effective[node.UID] = node.EffectiveStatus
+			todo := newSchemaTodo(node.Todo, node.EffectiveStatus)
+			if len(node.Children) > 0 {
+				todo.Children = convert(node.Children)
+			}
+			converted = append(converted, todo)
+		}
+		return converted
+	}
+	schema.Todos = convert(models.BuildHierarchy(result.AllTodos))
+
+	seen := make(map[string]bool)
+	for _, todo := range result.AffectedTodos {
+		if seen[todo.UID] {
+			continue
+		}
+		seen[todo.UID] = true
+
+		status, ok := effective[todo.UID]
+		if !ok {
+			status = string(todo.GetStatus())
+		}
+		schema.Affected = append(schema.Affected, newSchemaTodo(todo, status))
+	}
+
+	if result.Command == "search" {
+		ids := make(map[string]string, len(result.AllTodos))
+		for _, todo := range result.AllTodos {
+			ids[todo.UID] = todo.PositionPath
+		}
+		schema.Matches = make([]SchemaMatch, 0, len(result.Matches))
+		for _, match := range result.Matches {
+			schema.Matches = append(schema.Matches, SchemaMatch{
+				ID:    ids[match.UID],
+				UID:   match.UID,
+				Field: match.Field,
+				Score: match.Score,
+				Spans: match.Spans,
+			})
+		}
+	}
+
+	return schema
+}
+
+// SchemaEverywhereResult is what --format json and --format yaml print for
+// list --everywhere: each project's result, with project-qualified IDs
+type SchemaEverywhereResult struct {
+	SchemaVersion int              `json:"schemaVersion" yaml:"schemaVersion"`
+	Command       string           `json:"command" yaml:"command"`
+	Projects      []*SchemaProject `json:"projects" yaml:"projects"`
+}
+
+// SchemaProject is one project's part of a SchemaEverywhereResult
+type SchemaProject struct {
+	Name   string        `json:"name" yaml:"name"`
+	Root   string        `json:"root" yaml:"root"`
+	Result *SchemaResult `json:"result" yaml:"result"`
+}
+
+// NewSchemaEverywhereResult converts a listing across projects to the
+// versioned format
+func NewSchemaEverywhereResult(result *too.EverywhereResult) *SchemaEverywhereResult {
+	schema := &SchemaEverywhereResult{
+		SchemaVersion: SchemaVersion,
+		Command:       result.Command,
+		Projects:      []*SchemaProject{},
+	}
+	for _, project := range result.Projects {
+		schema.Projects = append(schema.Projects, &SchemaProject{
+			Name:   project.Project,
+			Root:   project.Root,
+			Result: NewSchemaResult(project.ChangeResult),
+		})
+	}
+	return schema
+}
+
+func newSchemaTodo(todo *models.Todo, effectiveStatus string) *SchemaTodo {
+	return &SchemaTodo{
+		ID:              todo.PositionPath,
+		UID:             todo.UID,
+		ShortID:         todo.ShortID,
+		ParentUID:       todo.ParentID,
+		Branch:          todo.Branch,
+		Text:            todo.Text,
+		Notes:           todo.Notes,
+		Status:          string(todo.GetStatus()),
+		EffectiveStatus: effectiveStatus,
+		Modified:        todo.Modified,
+	}
+}
@@ -0,0 +1,163 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func schemaTestResult() *too.ChangeResult {
+	modified := time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC)
+	parent := &models.Todo{UID: "p", Text: "Release", PositionPath: "1", Statuses: map[string]string{"completion": "pending"}, Modified: modified}
+	done := &models.Todo{UID: "a", ParentID: "p", Text: "Write notes", PositionPath: "1.c1", Statuses: map[string]string{"completion": "done"}, Modified: modified}
+	open := &models.Todo{UID: "b", ParentID: "p", Text: "Tag version", PositionPath: "1.1", Statuses: map[string]string{"completion": "pending"}, Modified: modified}
+
+	return &too.ChangeResult{
+		Command:       "complete",
+		AffectedTodos: []*models.Todo{done, done},
+		AllTodos:      []*models.Todo{parent, done, open},
+		TotalCount:    4,
+		DoneCount:     2,
+	}
+}
+
+func renderSchemaJSON(t *testing.T, result *too.ChangeResult) []byte {
+	t.Helper()
+
+	engine, err := output.NewEngine()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, engine.GetLipbalmEngine().Render(&buf, "json", result))
+	return buf.Bytes()
+}
+
+func TestSchemaResult(t *testing.T) {
+	t.Run("json output is locked down", func(t *testing.T) {
+		expected := `{
+  "schemaVersion": 1,
+  "command": "complete",
+  "message": "",
+  "counts": {
+    "total": 4,
+    "done": 2,
+    "pending": 2,
+    "shown": 3
+  },
+  "affected": [
+    {
+      "id": "1.c1",
+      "uid": "a",
+      "parentUid": "p",
+      "text": "Write notes",
+      "status": "done",
+      "effectiveStatus": "done",
+      "modified": "2025-03-14T10:00:00Z"
+    }
+  ],
+  "todos": [
+    {
+      "id": "1",
+      "uid": "p",
+      "text": "Release",
+      "status": "pending",
+      "effectiveStatus": "mixed",
+      "modified": "2025-03-14T10:00:00Z",
+      "children": [
+        {
+          "id": "1.c1",
+          "uid": "a",
+          "parentUid": "p",
+          "text": "Write notes",
+          "status": "done",
+          "effectiveStatus": "done",
+          "modified": "2025-03-14T10:00:00Z"
+        },
+        {
+          "id": "1.1",
+          "uid": "b",
+          "parentUid": "p",
+          "text": "Tag version",
+          "status": "pending",
+          "effectiveStatus": "pending",
+          "modified": "2025-03-14T10:00:00Z"
+        }
+      ]
+    }
+  ]
+}`
+		assert.JSONEq(t, expected, string(renderSchemaJSON(t, schemaTestResult())))
+	})
+
+	t.Run("empty results still have every field", func(t *testing.T) {
+		rendered := renderSchemaJSON(t, &too.ChangeResult{Command: "list"})
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(rendered, &decoded))
+		assert.Equal(t, []interface{}{}, decoded["affected"])
+		assert.Equal(t, []interface{}{}, decoded["todos"])
+	})
+
+	t.Run("yaml uses the same schema", func(t *testing.T) {
+		engine, err := output.NewEngine()
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, engine.GetLipbalmEngine().Render(&buf, "yaml", schemaTestResult()))
+
+		assert.Contains(t, buf.String(), "schemaVersion: 1")
+		assert.Contains(t, buf.String(), "effectiveStatus: mixed")
+		assert.Contains(t, buf.String(), "id: 1.c1")
+	})
+}
+
+// jsonSchemaObject is the part of a JSON Schema object definition the tests
+// compare against
+type jsonSchemaObject struct {
+	Required   []string                    `json:"required"`
+	Properties map[string]jsonSchemaObject `json:"properties"`
+	Defs       map[string]jsonSchemaObject `json:"$defs"`
+}
+
+func TestJSONSchemaDocument(t *testing.T) {
+	var schema jsonSchemaObject
+	require.NoError(t, json.Unmarshal(output.JSONSchema, &schema))
+
+	var rendered map[string]interface{}
+	require.NoError(t, json.Unmarshal(renderSchemaJSON(t, schemaTestResult()), &rendered))
+
+	// checkObject verifies that every emitted key is documented and every
+	// required key is emitted
+	checkObject := func(t *testing.T, name string, definition jsonSchemaObject, value map[string]interface{}) {
+		for key := range value {
+			assert.Contains(t, definition.Properties, key, "%s emits undocumented field %q", name, key)
+		}
+		for _, key := range definition.Required {
+			assert.Contains(t, value, key, "%s is missing required field %q", name, key)
+		}
+	}
+
+	checkObject(t, "result", schema, rendered)
+	checkObject(t, "counts", schema.Properties["counts"], rendered["counts"].(map[string]interface{}))
+
+	todoDefinition := schema.Defs["todo"]
+	var checkTodos func(todos []interface{})
+	checkTodos = func(todos []interface{}) {
+		for _, item := range todos {
+			todo := item.(map[string]interface{})
+			checkObject(t, "todo", todoDefinition, todo)
+			if children, ok := todo["children"].([]interface{}); ok {
+				checkTodos(children)
+			}
+		}
+	}
+	checkTodos(rendered["todos"].([]interface{}))
+	checkTodos(rendered["affected"].([]interface{}))
+
+	assert.Equal(t, float64(output.SchemaVersion), rendered["schemaVersion"])
+}
@@ -151,6 +151,16 @@ func GetLipbalmStyleMap() lipbalm.StyleMap {
 	styles["highlighted-todo"] = lipgloss.NewStyle().
 		Foreground(PRIMARY_TEXT)
 	
+	// Matched spans in search results
+	styles["match"] = lipgloss.NewStyle().
+		Foreground(ACCENT_COLOR).
+		Bold(true)
+	
+	// Project headings in listings across projects
+	styles["project"] = lipgloss.NewStyle().
+		Foreground(ACCENT_COLOR).
+		Bold(true)
+	
 	// Override the default muted style to use our MUTED_TEXT
 	styles["muted"] = lipgloss.NewStyle().
 		Foreground(MUTED_TEXT)
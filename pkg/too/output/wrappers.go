@@ -7,4 +7,20 @@ import (
 // ChangeResultContextual is a wrapper for ChangeResult that triggers the contextual template
 type ChangeResultContextual struct {
 	*too.ChangeResult
+}
+
+// SearchResults is a wrapper for search results that triggers the search
+// template, which shows the matches among their dimmed ancestors
+type SearchResults struct {
+	*too.ChangeResult
+	MatchesByUID map[string]*too.SearchMatch
+}
+
+// NewSearchResults wraps a search result, indexing its matches by UID
+func NewSearchResults(result *too.ChangeResult) *SearchResults {
+	matches := make(map[string]*too.SearchMatch, len(result.Matches))
+	for i := range result.Matches {
+		matches[result.Matches[i].UID] = &result.Matches[i]
+	}
+	return &SearchResults{ChangeResult: result, MatchesByUID: matches}
 }
\ No newline at end of file
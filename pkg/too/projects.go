@@ -0,0 +1,308 @@
+package too
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/scope"
+)
+
+// ProjectRefSeparator joins a project name and a reference, as in "api:3"
+const ProjectRefSeparator = ":"
+
+// ProjectResult is one project's part of a listing across projects. The
+// todos' position paths are qualified with the project name, as in "api:3",
+// so they can be passed back to commands from anywhere.
+type ProjectResult struct {
+	Project string
+	Root    string
+	*ChangeResult
+}
+
+// EverywhereResult is a listing of several stores under headings: every
+// registered project, or a project and the global store
+type EverywhereResult struct {
+	Command  string
+	Projects []*ProjectResult // Only the projects with todos to show
+}
+
+// ListEverywhere runs list with opts on every project in the registry at
+// registryPath. Projects whose store is gone are dropped from the registry.
+func ListEverywhere(registryPath string, opts map[string]interface{}) (*EverywhereResult, error) {
+	registry, err := scope.LoadRegistry(registryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EverywhereResult{Command: "list"}
+	pruned := false
+	for _, project := range append([]*scope.Project(nil), registry.Projects...) {
+		if _, err := os.Stat(project.Path); os.IsNotExist(err) {
+			registry.Remove(project.Path)
+			pruned = true
+			continue
+		}
+
+		section, err := listStore(project.Path, project.Name, project.Name, project.Root, opts)
+		if err != nil {
+			return nil, err
+		}
+		if section != nil {
+			result.Projects = append(result.Projects, section)
+		}
+	}
+
+	if pruned {
+		if err := registry.Save(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ListWithGlobal lists the store at collectionPath followed by the global
+// store, each under a heading. The global todos' positions are qualified
+// with "g:". Outside a project, only the global store is listed.
+func ListWithGlobal(registryPath string, opts map[string]interface{}) (*EverywhereResult, error) {
+	result := &EverywhereResult{Command: "list"}
+	globalPath := scope.GlobalPath()
+
+	collectionPath, _ := opts["collectionPath"].(string)
+	if collectionPath != globalPath {
+		registry, err := scope.LoadRegistry(registryPath)
+		if err != nil {
+			return nil, err
+		}
+		name, root := filepath.Base(filepath.Dir(collectionPath)), filepath.Dir(collectionPath)
+		for _, project := range registry.Projects {
+			if project.Path == collectionPath {
+				name, root = project.Name, project.Root
+			}
+		}
+
+		section, err := listStore(collectionPath, name, "", root, opts)
+		if err != nil {
+			return nil, err
+		}
+		if section != nil {
+			result.Projects = append(result.Projects, section)
+		}
+	}
+
+	if _, err := os.Stat(globalPath); err == nil {
+		section, err := listStore(globalPath, "global", scope.GlobalName, filepath.Dir(globalPath), opts)
+		if err != nil {
+			return nil, err
+		}
+		if section != nil {
+			result.Projects = append(result.Projects, section)
+		}
+	}
+	return result, nil
+}
+
+// ListRecursive lists the store at collectionPath followed by the stores of
+// the sub-scopes below the current directory, each under its directory
+// relative to the current one. Sub-scope positions are qualified with that
+// directory, as in "services/api:3".
+func ListRecursive(opts map[string]interface{}) (*EverywhereResult, error) {
+	result := &EverywhereResult{Command: "list"}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	collectionPath, _ := opts["collectionPath"].(string)
+	subScopes, err := scope.FindSubScopes(currentDir)
+	if err != nil {
+		return nil, err
+	}
+
+	stores := []string{collectionPath}
+	for _, path := range subScopes {
+		if !samePath(path, collectionPath) {
+			stores = append(stores, path)
+		}
+	}
+
+	for i, path := range stores {
+		root := filepath.Dir(path)
+		if absRoot, err := filepath.Abs(root); err == nil {
+			root = absRoot
+		}
+		heading, err := filepath.Rel(currentDir, root)
+		if err != nil {
+			heading = root
+		}
+		heading = filepath.ToSlash(heading)
+
+		qualifier := heading
+		if i == 0 {
+			qualifier = ""
+		}
+		section, err := listStore(path, heading, qualifier, root, opts)
+		if err != nil {
+			return nil, err
+		}
+		if section != nil {
+			result.Projects = append(result.Projects, section)
+		}
+	}
+	return result, nil
+}
+
+// subScopeStore returns the store of the sub-scope in dir, relative to the
+// current directory, or an empty string when there is none
+func subScopeStore(dir string) string {
+	path := filepath.Join(filepath.FromSlash(dir), scope.StoreFile)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return ""
+	}
+	return path
+}
+
+// listStore runs list with opts on the store at path, qualifying positions
+// with qualifier unless it is empty. It returns nil when there is nothing to
+// show.
+func listStore(path, name, qualifier, root string, opts map[string]interface{}) (*ProjectResult, error) {
+	storeOpts := make(map[string]interface{}, len(opts))
+	for key, value := range opts {
+		storeOpts[key] = value
+	}
+	storeOpts["collectionPath"] = path
+
+	listing, err := ExecuteUnifiedCommand("list", nil, storeOpts)
+	if err != nil {
+		return nil, err
+	}
+	if len(listing.AllTodos) == 0 {
+		return nil, nil
+	}
+	if qualifier != "" {
+		qualifyPositions(qualifier, listing.AllTodos)
+	}
+	return &ProjectResult{
+		Project:      name,
+		Root:         root,
+		ChangeResult: listing,
+	}, nil
+}
+
+// qualifyPositions prefixes the position paths of todos with project
+func qualifyPositions(project string, todos []*models.Todo) {
+	for _, todo := range todos {
+		todo.PositionPath = project + ProjectRefSeparator + todo.PositionPath
+	}
+}
+
+// SplitProjectRef splits a reference qualified with a project name, like
+// "api:3", into the name and the reference. Only position paths, short IDs
+// and symbolic references are qualified: "api: fix login" is free text.
+// Symbolic relations like "next:3" are not project names.
+func SplitProjectRef(ref string) (project, rest string, ok bool) {
+	i := strings.Index(ref, ProjectRefSeparator)
+	if i <= 0 || i == len(ref)-1 {
+		return "", ref, false
+	}
+	for _, prefix := range symbolicPrefixes {
+		if strings.HasPrefix(ref, prefix) {
+			return "", ref, false
+		}
+	}
+	rest = ref[i+1:]
+	if !looksLikePositionPath(rest) && !isShortIDRef(rest) && !isSymbolicRef(rest) {
+		return "", ref, false
+	}
+	return ref[:i], rest, true
+}
+
+// ReferenceArgCount is how many of a command's leading args are todo
+// references: all of them for complete and reopen, the todo for edit, the
+// todo and destination for move, and none for the others.
+func ReferenceArgCount(cmdName string, nargs int) int {
+	cmd, name, ok := LookupUnifiedCommand(cmdName)
+	if !ok || !cmd.RequiresRef {
+		return 0
+	}
+	switch name {
+	case "edit":
+		return min(1, nargs)
+	case "move":
+		return min(2, nargs)
+	}
+	return nargs
+}
+
+// projectForRefs finds the references of a command that are qualified with
+// a registered project name, "g" for the global store or the directory of a
+// sub-scope below the current one, and returns the project's store with
+// args and opts rewritten to references within it. It returns an empty path
+// when no reference names a project. Unqualified references only mix with
+// qualified ones naming the current project. Args and opts are copied, not
+// changed.
+func projectForRefs(registryPath, cmdName string, args []string, opts map[string]interface{}) (string, []string, map[string]interface{}, error) {
+	parent, _ := opts["parent"].(string)
+	refs := args[:ReferenceArgCount(cmdName, len(args))]
+	if !strings.Contains(parent+strings.Join(refs, " "), ProjectRefSeparator) {
+		return "", args, opts, nil
+	}
+
+	registry, err := scope.LoadRegistry(registryPath)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	global := &scope.Project{Name: scope.GlobalName, Path: scope.GlobalPath()}
+	var project *scope.Project
+	local := 0
+	unqualify := func(ref string) (string, error) {
+		name, rest, ok := SplitProjectRef(ref)
+		named, found := registry.Find(name)
+		if name == scope.GlobalName {
+			named, found = global, true
+		}
+		if ok && !found {
+			if path := subScopeStore(name); path != "" {
+				named, found = &scope.Project{Name: name, Path: path}, true
+			}
+		}
+		if !ok || !found {
+			local++
+			return ref, nil
+		}
+		if project != nil && project.Path != named.Path {
+			return "", NewValidationError("references to both %s and %s: one command works in one project", project.Name, named.Name)
+		}
+		project = named
+		return rest, nil
+	}
+
+	newArgs := append([]string(nil), args...)
+	for i := range refs {
+		if newArgs[i], err = unqualify(args[i]); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	newOpts := opts
+	if parent != "" {
+		unqualified, err := unqualify(parent)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		newOpts = make(map[string]interface{}, len(opts))
+		for key, value := range opts {
+			newOpts[key] = value
+		}
+		newOpts["parent"] = unqualified
+	}
+
+	if project == nil {
+		return "", args, opts, nil
+	}
+	if current, _ := opts["collectionPath"].(string); local > 0 && project.Path != current {
+		return "", nil, nil, NewValidationError("qualify every reference with %s%s, or none: one command works in one project", project.Name, ProjectRefSeparator)
+	}
+	return project.Path, newArgs, newOpts, nil
+}
@@ -0,0 +1,298 @@
+package too
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/scope"
+	"github.com/arthur-debert/too/pkg/too/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupProjects registers an api and a web project in a fresh registry and
+// returns the registry's path and the projects' stores
+func setupProjects(t *testing.T) (string, string, string) {
+	t.Helper()
+
+	api, apiPath := testutil.CreateStoreWithSpecs(t,
+		testutil.TodoSpec{Text: "Fix login"},
+		testutil.TodoSpec{Text: "Rotate keys"},
+		testutil.TodoSpec{Text: "Write test", ParentPos: "1"},
+	)
+	require.NoError(t, api.Close())
+	web, webPath := testutil.CreateStoreWithSpecs(t, testutil.TodoSpec{Text: "New homepage"})
+	require.NoError(t, web.Close())
+
+	registryPath := filepath.Join(t.TempDir(), "projects.json")
+	registry, err := scope.LoadRegistry(registryPath)
+	require.NoError(t, err)
+	registry.Add("/src/api", apiPath)
+	registry.Add("/src/web", webPath)
+	require.NoError(t, registry.Save())
+	return registryPath, apiPath, webPath
+}
+
+func TestListEverywhere(t *testing.T) {
+	t.Run("lists every project with qualified positions", func(t *testing.T) {
+		registryPath, _, _ := setupProjects(t)
+
+		result, err := ListEverywhere(registryPath, map[string]interface{}{})
+		require.NoError(t, err)
+		require.Len(t, result.Projects, 2)
+
+		api := result.Projects[0]
+		assert.Equal(t, "api", api.Project)
+		assert.Equal(t, "/src/api", api.Root)
+		var paths []string
+		for _, todo := range api.AllTodos {
+			paths = append(paths, todo.PositionPath)
+		}
+		assert.ElementsMatch(t, []string{"api:1", "api:1.1", "api:2"}, paths)
+		assert.Equal(t, "web:1", result.Projects[1].AllTodos[0].PositionPath)
+	})
+
+	t.Run("projects without todos to show are left out", func(t *testing.T) {
+		registryPath, _, webPath := setupProjects(t)
+		_, err := ExecuteUnifiedCommand("complete", []string{"1"}, map[string]interface{}{"collectionPath": webPath})
+		require.NoError(t, err)
+
+		result, err := ListEverywhere(registryPath, map[string]interface{}{})
+		require.NoError(t, err)
+		require.Len(t, result.Projects, 1)
+		assert.Equal(t, "api", result.Projects[0].Project)
+	})
+
+	t.Run("missing stores are dropped from the registry", func(t *testing.T) {
+		registryPath, _, _ := setupProjects(t)
+		registry, err := scope.LoadRegistry(registryPath)
+		require.NoError(t, err)
+		registry.Add("/src/gone", "/src/gone/.todos.json")
+		require.NoError(t, registry.Save())
+
+		result, err := ListEverywhere(registryPath, map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Len(t, result.Projects, 2)
+
+		registry, err = scope.LoadRegistry(registryPath)
+		require.NoError(t, err)
+		_, found := registry.Find("gone")
+		assert.False(t, found)
+	})
+}
+
+// setupGlobal points the global store at a temporary directory and adds
+// todos to it
+func setupGlobal(t *testing.T, texts ...string) string {
+	t.Helper()
+
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	globalPath := scope.GlobalPath()
+	require.NoError(t, os.MkdirAll(filepath.Dir(globalPath), 0755))
+	for _, text := range texts {
+		_, err := ExecuteUnifiedCommand("add", []string{text}, map[string]interface{}{"collectionPath": globalPath})
+		require.NoError(t, err)
+	}
+	return globalPath
+}
+
+func TestListWithGlobal(t *testing.T) {
+	t.Run("lists the project, then the global todos", func(t *testing.T) {
+		registryPath, apiPath, _ := setupProjects(t)
+		setupGlobal(t, "Call mom", "Renew passport")
+
+		result, err := ListWithGlobal(registryPath, map[string]interface{}{"collectionPath": apiPath})
+		require.NoError(t, err)
+		require.Len(t, result.Projects, 2)
+
+		project := result.Projects[0]
+		assert.Equal(t, "api", project.Project)
+		assert.Equal(t, "/src/api", project.Root)
+		assert.Len(t, project.AllTodos, 3)
+		for _, todo := range project.AllTodos {
+			assert.NotContains(t, todo.PositionPath, ProjectRefSeparator)
+		}
+
+		global := result.Projects[1]
+		assert.Equal(t, "global", global.Project)
+		var paths []string
+		for _, todo := range global.AllTodos {
+			paths = append(paths, todo.PositionPath)
+		}
+		assert.Equal(t, []string{"g:1", "g:2"}, paths)
+	})
+
+	t.Run("outside a project only the global todos are listed", func(t *testing.T) {
+		registryPath, _, _ := setupProjects(t)
+		globalPath := setupGlobal(t, "Call mom")
+
+		result, err := ListWithGlobal(registryPath, map[string]interface{}{"collectionPath": globalPath})
+		require.NoError(t, err)
+		require.Len(t, result.Projects, 1)
+		assert.Equal(t, "global", result.Projects[0].Project)
+	})
+
+	t.Run("a missing global store is left out", func(t *testing.T) {
+		registryPath, apiPath, _ := setupProjects(t)
+		t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+		result, err := ListWithGlobal(registryPath, map[string]interface{}{"collectionPath": apiPath})
+		require.NoError(t, err)
+		require.Len(t, result.Projects, 1)
+		assert.Equal(t, "api", result.Projects[0].Project)
+	})
+}
+
+func TestListRecursive(t *testing.T) {
+	// setupMonorepo creates todos at a repository's root, in services/api
+	// and in services/web, and moves into the root
+	setupMonorepo := func(t *testing.T) string {
+		t.Helper()
+		t.Setenv("XDG_DATA_HOME", t.TempDir())
+		root := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+		for dir, text := range map[string]string{"": "Release", "services/api": "Fix login", "services/web": "New homepage"} {
+			path := filepath.Join(root, dir, scope.StoreFile)
+			_, err := ExecuteUnifiedCommand("add", []string{text}, map[string]interface{}{"collectionPath": path})
+			require.NoError(t, err)
+		}
+		t.Chdir(root)
+		return root
+	}
+
+	t.Run("lists the sub-scopes under their directories", func(t *testing.T) {
+		root := setupMonorepo(t)
+
+		result, err := ListRecursive(map[string]interface{}{"collectionPath": filepath.Join(root, scope.StoreFile)})
+		require.NoError(t, err)
+		require.Len(t, result.Projects, 3)
+
+		assert.Equal(t, ".", result.Projects[0].Project)
+		assert.Equal(t, "1", result.Projects[0].AllTodos[0].PositionPath)
+		assert.Equal(t, "services/api", result.Projects[1].Project)
+		assert.Equal(t, filepath.Join(root, "services", "api"), result.Projects[1].Root)
+		assert.Equal(t, "services/api:1", result.Projects[1].AllTodos[0].PositionPath)
+		assert.Equal(t, "services/web:1", result.Projects[2].AllTodos[0].PositionPath)
+	})
+
+	t.Run("sub-scope refs run in their store", func(t *testing.T) {
+		root := setupMonorepo(t)
+		opts := map[string]interface{}{"collectionPath": filepath.Join(root, scope.StoreFile)}
+
+		result, err := ExecuteUnifiedCommand("complete", []string{"services/api:1"}, opts)
+		require.NoError(t, err)
+		require.Len(t, result.AffectedTodos, 1)
+		assert.Equal(t, "Fix login", result.AffectedTodos[0].Text)
+
+		_, err = ExecuteUnifiedCommand("complete", []string{"services/api:1", "services/web:1"}, opts)
+		assert.Equal(t, ErrValidation, KindOf(err))
+	})
+}
+
+func TestProjectRefs(t *testing.T) {
+	t.Run("split project refs", func(t *testing.T) {
+		tests := []struct {
+			ref, project, rest string
+			ok                 bool
+		}{
+			{"api:3", "api", "3", true},
+			{"api:1.2", "api", "1.2", true},
+			{"home/api:@a3f9", "home/api", "@a3f9", true},
+			{"3", "", "3", false},
+			{"next:3", "", "next:3", false},
+			{"api:", "", "api:", false},
+			{":3", "", ":3", false},
+			{"api:last", "api", "last", true},
+			{"api:next:2^", "api", "next:2^", true},
+			{"api: fix login", "", "api: fix login", false},
+			{"api:phone", "", "api:phone", false},
+			{"g:@phone", "", "g:@phone", false},
+		}
+		for _, tt := range tests {
+			project, rest, ok := SplitProjectRef(tt.ref)
+			assert.Equal(t, tt.ok, ok, tt.ref)
+			assert.Equal(t, tt.project, project, tt.ref)
+			assert.Equal(t, tt.rest, rest, tt.ref)
+		}
+	})
+
+	t.Run("qualified refs run in their project", func(t *testing.T) {
+		registryPath, apiPath, webPath := setupProjects(t)
+		opts := map[string]interface{}{"collectionPath": webPath}
+
+		path, args, newOpts, err := projectForRefs(registryPath, "complete", []string{"api:1.1", "api:2"}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, apiPath, path)
+		assert.Equal(t, []string{"1.1", "2"}, args)
+		assert.Equal(t, opts, newOpts)
+	})
+
+	t.Run("text naming a project stays in the current one", func(t *testing.T) {
+		registryPath, _, webPath := setupProjects(t)
+		opts := map[string]interface{}{"collectionPath": webPath}
+
+		path, args, _, err := projectForRefs(registryPath, "complete", []string{"api: fix login"}, opts)
+		require.NoError(t, err)
+		assert.Empty(t, path)
+		assert.Equal(t, []string{"api: fix login"}, args)
+	})
+
+	t.Run("g: refs run in the global store", func(t *testing.T) {
+		registryPath, _, webPath := setupProjects(t)
+		globalPath := setupGlobal(t, "Call mom", "Renew passport")
+
+		result, err := ExecuteUnifiedCommand("complete", []string{"g:2"}, map[string]interface{}{"collectionPath": webPath})
+		require.NoError(t, err)
+		require.Len(t, result.AffectedTodos, 1)
+		assert.Equal(t, "Renew passport", result.AffectedTodos[0].Text)
+
+		path, args, _, err := projectForRefs(registryPath, "complete", []string{"g:1"}, map[string]interface{}{"collectionPath": webPath})
+		require.NoError(t, err)
+		assert.Equal(t, globalPath, path)
+		assert.Equal(t, []string{"1"}, args)
+	})
+
+	t.Run("a qualified parent routes add", func(t *testing.T) {
+		registryPath, apiPath, webPath := setupProjects(t)
+		opts := map[string]interface{}{"collectionPath": webPath, "parent": "api:1"}
+
+		path, args, newOpts, err := projectForRefs(registryPath, "add", []string{"Check logs"}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, apiPath, path)
+		assert.Equal(t, []string{"Check logs"}, args)
+		assert.Equal(t, "1", newOpts["parent"])
+		assert.Equal(t, "api:1", opts["parent"], "opts are not changed")
+	})
+
+	t.Run("text that isn't a ref is left alone", func(t *testing.T) {
+		registryPath, _, webPath := setupProjects(t)
+		opts := map[string]interface{}{"collectionPath": webPath}
+
+		path, args, _, err := projectForRefs(registryPath, "edit", []string{"1", "api: fix it"}, opts)
+		require.NoError(t, err)
+		assert.Empty(t, path)
+		assert.Equal(t, []string{"1", "api: fix it"}, args)
+
+		path, _, _, err = projectForRefs(registryPath, "complete", []string{"docs:1"}, opts)
+		require.NoError(t, err)
+		assert.Empty(t, path, "docs isn't a registered project")
+	})
+
+	t.Run("one command works in one project", func(t *testing.T) {
+		registryPath, _, webPath := setupProjects(t)
+		opts := map[string]interface{}{"collectionPath": webPath}
+
+		_, _, _, err := projectForRefs(registryPath, "complete", []string{"api:1", "web:1"}, opts)
+		assert.Equal(t, ErrValidation, KindOf(err))
+
+		_, _, _, err = projectForRefs(registryPath, "complete", []string{"api:1", "1"}, opts)
+		assert.Equal(t, ErrValidation, KindOf(err))
+
+		// Unqualified refs are fine in the current project
+		path, args, _, err := projectForRefs(registryPath, "complete", []string{"web:1", "1"}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, webPath, path)
+		assert.Equal(t, []string{"1", "1"}, args)
+	})
+}
@@ -12,6 +12,8 @@ type ChangeResult struct {
 	AllTodos       []*models.Todo   // All todos in the collection after the change
 	TotalCount     int                 // Total number of todos
 	DoneCount      int                 // Number of completed todos
+	Matches        []SearchMatch       // Search hits, best first; AllTodos also holds their ancestors
+	Warnings       []string            // Things the user should know about, like a stale listing
 }
 
 // MessageType returns the appropriate message type for this result
@@ -21,7 +23,7 @@ func (r *ChangeResult) MessageType() string {
 		return "info"
 	case "reopen":
 		return "warning"
-	case "clean":
+	case "clean", "branch-clean":
 		if len(r.AffectedTodos) == 0 {
 			return "warning"
 		}
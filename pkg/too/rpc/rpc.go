@@ -0,0 +1,322 @@
+// Package rpc speaks line-delimited JSON-RPC 2.0 so editor plugins can keep
+// one too process around instead of starting a new one per action.
+//
+// Every method is a unified command, called by name or alias. Params are
+// either an array of arguments, or an object whose "args" member holds the
+// arguments and whose other members are the command options:
+//
+//	{"jsonrpc": "2.0", "id": 1, "method": "add", "params": {"args": ["Milk"], "parent": "1"}}
+//	{"jsonrpc": "2.0", "id": 2, "method": "complete", "params": ["1.1"]}
+//
+// Results are the same JSON that `--format json` prints. When the data file
+// is changed by someone else the server sends a collectionChanged
+// notification so plugins know to refresh.
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/lipbalm"
+	"github.com/arthur-debert/too/pkg/logging"
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/output"
+	"github.com/rs/zerolog"
+)
+
+// Standard JSON-RPC 2.0 error codes, plus CodeCommandFailed for errors
+// returned by the command itself
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeCommandFailed  = -32000
+)
+
+// ChangedNotification is the method of the notification sent when the data
+// file changes outside of this session
+const ChangedNotification = "collectionChanged"
+
+// DefaultWatchInterval is how often the data file is checked for changes
+const DefaultWatchInterval = 500 * time.Millisecond
+
+// Request is an incoming JSON-RPC request or notification
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is an outgoing JSON-RPC response
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Notification is an outgoing JSON-RPC notification
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// Error is a JSON-RPC error object. Data holds the error's kind, exit code
+// and, for ambiguous references, the candidates, as in `--format json`.
+type Error struct {
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Server answers JSON-RPC requests for one collection
+type Server struct {
+	engine         *too.NanoEngine
+	renderer       *lipbalm.RenderEngine
+	collectionPath string
+	logger         zerolog.Logger
+
+	// WatchInterval is how often the data file is polled for changes,
+	// zero disables change notifications
+	WatchInterval time.Duration
+
+	// mu serializes commands and guards the last seen file state
+	mu       sync.Mutex
+	lastSeen fileState
+
+	// writeMu keeps responses and notifications from interleaving
+	writeMu sync.Mutex
+}
+
+// fileState identifies a version of the data file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// New creates a server that runs commands against the given engine. The
+// caller owns the engine and closes it once the server is done.
+func New(engine *too.NanoEngine, collectionPath string) (*Server, error) {
+	outputEngine, err := output.NewEngine()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create render engine: %w", err)
+	}
+
+	return &Server{
+		engine:         engine,
+		renderer:       outputEngine.GetLipbalmEngine(),
+		collectionPath: collectionPath,
+		logger:         logging.GetLogger("too.rpc"),
+		WatchInterval:  DefaultWatchInterval,
+	}, nil
+}
+
+// Serve reads one request per line from in and writes one response per line
+// to out, until in is exhausted or ctx is cancelled
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	s.lastSeen = s.stat()
+	s.mu.Unlock()
+
+	if s.WatchInterval > 0 {
+		go s.watch(ctx, out)
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		if response := s.handle(line); response != nil {
+			if err := s.write(out, response); err != nil {
+				return err
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers one request line, returning nil for notifications
+func (s *Server) handle(line []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return &Response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: CodeParseError, Message: err.Error()}}
+	}
+
+	result, rpcErr := s.call(&req)
+
+	// Requests without an id are notifications and get no response
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	response := &Response{JSONRPC: "2.0", ID: req.ID}
+	if rpcErr != nil {
+		response.Error = rpcErr
+	} else {
+		response.Result = result
+	}
+	return response
+}
+
+// call runs the unified command named by the request
+func (s *Server) call(req *Request) (json.RawMessage, *Error) {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return nil, &Error{Code: CodeInvalidRequest, Message: "invalid request: jsonrpc must be \"2.0\" and method is required"}
+	}
+
+	if _, _, ok := too.LookupUnifiedCommand(req.Method); !ok {
+		return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+
+	args, opts, err := parseParams(req.Params)
+	if err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	s.logger.Debug().Str("method", req.Method).Strs("args", args).Msg("call")
+
+	s.mu.Lock()
+	result, err := too.ExecuteUnifiedCommandWithEngine(s.engine, req.Method, args, opts)
+	// Our own writes are not news to the client
+	s.lastSeen = s.stat()
+	s.mu.Unlock()
+
+	if err != nil {
+		rpcErr := &Error{Code: CodeCommandFailed, Message: err.Error()}
+		var typed *too.Error
+		if errors.As(err, &typed) {
+			rpcErr.Data = typed.ErrorFields()
+		}
+		return nil, rpcErr
+	}
+
+	var buf bytes.Buffer
+	if err := s.renderer.Render(&buf, "json", result); err != nil {
+		return nil, &Error{Code: CodeCommandFailed, Message: fmt.Sprintf("failed to render result: %v", err)}
+	}
+
+	// Keep the response on a single line
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, buf.Bytes()); err != nil {
+		return nil, &Error{Code: CodeCommandFailed, Message: fmt.Sprintf("failed to render result: %v", err)}
+	}
+	return compact.Bytes(), nil
+}
+
+// parseParams splits params into command arguments and options
+func parseParams(params json.RawMessage) ([]string, map[string]interface{}, error) {
+	opts := map[string]interface{}{}
+	if len(params) == 0 || string(params) == "null" {
+		return nil, opts, nil
+	}
+
+	var args []string
+	if err := json.Unmarshal(params, &args); err == nil {
+		return args, opts, nil
+	}
+
+	if err := json.Unmarshal(params, &opts); err != nil {
+		return nil, nil, fmt.Errorf("params must be an array of arguments or an object")
+	}
+
+	if raw, ok := opts["args"]; ok {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("args must be an array of strings")
+		}
+		for _, item := range list {
+			arg, ok := item.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("args must be an array of strings")
+			}
+			args = append(args, arg)
+		}
+		delete(opts, "args")
+	}
+
+	// The collection is fixed for the session
+	delete(opts, "collectionPath")
+
+	return args, opts, nil
+}
+
+// watch polls the data file and notifies the client when it changes
+func (s *Server) watch(ctx context.Context, out io.Writer) {
+	ticker := time.NewTicker(s.WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			current := s.stat()
+			changed := current != s.lastSeen
+			s.lastSeen = current
+			s.mu.Unlock()
+
+			if !changed {
+				continue
+			}
+
+			notification := &Notification{
+				JSONRPC: "2.0",
+				Method:  ChangedNotification,
+				Params:  map[string]string{"path": s.collectionPath},
+			}
+			if err := s.write(out, notification); err != nil {
+				s.logger.Debug().Err(err).Msg("failed to send change notification")
+				return
+			}
+		}
+	}
+}
+
+// stat returns the current state of the data file, zero if it is missing
+func (s *Server) stat() fileState {
+	info, err := os.Stat(s.collectionPath)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
+
+// write sends one message as a single line
+func (s *Server) write(out io.Writer, message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err = out.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,166 @@
+package rpc_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) (*rpc.Server, string) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "test.json")
+	engine, err := too.NewNanoEngine(dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = engine.Close() })
+
+	server, err := rpc.New(engine, dbPath)
+	require.NoError(t, err)
+	return server, dbPath
+}
+
+// serveLines runs the given request lines and returns the decoded responses
+func serveLines(t *testing.T, server *rpc.Server, lines ...string) []rpc.Response {
+	t.Helper()
+
+	var out strings.Builder
+	err := server.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")), &out)
+	require.NoError(t, err)
+
+	var responses []rpc.Response
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var response rpc.Response
+		require.NoError(t, json.Unmarshal([]byte(line), &response), line)
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+func TestServe(t *testing.T) {
+	t.Run("calls unified commands", func(t *testing.T) {
+		server, _ := newTestServer(t)
+		server.WatchInterval = 0
+
+		responses := serveLines(t, server,
+			`{"jsonrpc": "2.0", "id": 1, "method": "add", "params": ["Groceries"]}`,
+			`{"jsonrpc": "2.0", "id": 2, "method": "add", "params": {"args": ["Milk"], "parent": "1"}}`,
+			`{"jsonrpc": "2.0", "id": 3, "method": "c", "params": ["1.1"]}`,
+			`{"jsonrpc": "2.0", "id": 4, "method": "list", "params": {"all": true}}`,
+		)
+		require.Len(t, responses, 4)
+
+		for _, response := range responses {
+			assert.Nil(t, response.Error)
+		}
+		assert.Equal(t, "4", string(responses[3].ID))
+
+		var list struct {
+			Command string `json:"command"`
+			Counts  struct {
+				Done  int `json:"done"`
+				Shown int `json:"shown"`
+			} `json:"counts"`
+		}
+		require.NoError(t, json.Unmarshal(responses[3].Result, &list))
+		assert.Equal(t, "list", list.Command)
+		assert.Equal(t, 2, list.Counts.Shown)
+		// Completing the only child completes its parent as well
+		assert.Equal(t, 2, list.Counts.Done)
+	})
+
+	t.Run("reports structured errors", func(t *testing.T) {
+		server, _ := newTestServer(t)
+		server.WatchInterval = 0
+
+		responses := serveLines(t, server,
+			`not json`,
+			`{"jsonrpc": "2.0", "id": 1, "method": "fly"}`,
+			`{"jsonrpc": "2.0", "id": 2, "method": "add", "params": 42}`,
+			`{"jsonrpc": "2.0", "id": 3, "method": "complete", "params": ["9"]}`,
+			`{"jsonrpc": "1.0", "id": 4, "method": "list"}`,
+		)
+		require.Len(t, responses, 5)
+
+		assert.Equal(t, rpc.CodeParseError, responses[0].Error.Code)
+		assert.Equal(t, "null", string(responses[0].ID))
+		assert.Equal(t, rpc.CodeMethodNotFound, responses[1].Error.Code)
+		assert.Equal(t, rpc.CodeInvalidParams, responses[2].Error.Code)
+		assert.Equal(t, rpc.CodeCommandFailed, responses[3].Error.Code)
+		assert.Contains(t, responses[3].Error.Message, "9")
+		assert.Equal(t, "not_found", responses[3].Error.Data["kind"])
+		assert.Equal(t, rpc.CodeInvalidRequest, responses[4].Error.Code)
+	})
+
+	t.Run("does not answer notifications", func(t *testing.T) {
+		server, _ := newTestServer(t)
+		server.WatchInterval = 0
+
+		responses := serveLines(t, server,
+			`{"jsonrpc": "2.0", "method": "add", "params": ["Quiet"]}`,
+			`{"jsonrpc": "2.0", "id": 1, "method": "list"}`,
+		)
+		require.Len(t, responses, 1)
+
+		var list struct {
+			Todos []struct {
+				Text string `json:"text"`
+			} `json:"todos"`
+		}
+		require.NoError(t, json.Unmarshal(responses[0].Result, &list))
+		require.Len(t, list.Todos, 1)
+		assert.Equal(t, "Quiet", list.Todos[0].Text)
+	})
+
+	t.Run("notifies when the data file changes", func(t *testing.T) {
+		server, dbPath := newTestServer(t)
+		server.WatchInterval = 10 * time.Millisecond
+
+		inReader, inWriter := io.Pipe()
+		outReader, outWriter := io.Pipe()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() { done <- server.Serve(ctx, inReader, outWriter) }()
+
+		// Our own changes are not reported
+		_, err := inWriter.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "method": "add", "params": ["Mine"]}` + "\n"))
+		require.NoError(t, err)
+		lines := bufio.NewScanner(outReader)
+		require.True(t, lines.Scan())
+		assert.Contains(t, lines.Text(), `"id":1`)
+
+		// Someone else writes to the store
+		other, err := too.NewNanoEngine(dbPath)
+		require.NoError(t, err)
+		_, err = too.ExecuteUnifiedCommandWithEngine(other, "add", []string{"Theirs"}, map[string]interface{}{})
+		require.NoError(t, err)
+		require.NoError(t, other.Close())
+		later := time.Now().Add(time.Second)
+		require.NoError(t, os.Chtimes(dbPath, later, later))
+
+		require.True(t, lines.Scan())
+		var notification rpc.Notification
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &notification))
+		assert.Equal(t, rpc.ChangedNotification, notification.Method)
+
+		cancel()
+		require.NoError(t, inWriter.Close())
+		go func() { _, _ = io.Copy(io.Discard, outReader) }()
+		require.NoError(t, <-done)
+	})
+}
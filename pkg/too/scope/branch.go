@@ -0,0 +1,97 @@
+package scope
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// branchRefPrefix starts the refs of local branches
+const branchRefPrefix = "refs/heads/"
+
+// CurrentBranch returns the branch checked out in the git worktree
+// containing dir, read from HEAD. It is empty outside git and on a detached
+// HEAD.
+func CurrentBranch(dir string) string {
+	gitDir, ok := gitDirAbove(dir)
+	if !ok {
+		return ""
+	}
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref, found := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	if !found {
+		return ""
+	}
+	return strings.TrimPrefix(ref, branchRefPrefix)
+}
+
+// LocalBranches returns the local branches of the git repository containing
+// dir, from its loose and packed refs
+func LocalBranches(dir string) ([]string, error) {
+	gitDir, ok := gitDirAbove(dir)
+	if !ok {
+		return nil, fmt.Errorf("%s is not in a git repository", dir)
+	}
+	commonDir := commonDirOf(gitDir)
+
+	branches := make(map[string]bool)
+	headsDir := filepath.Join(commonDir, filepath.FromSlash(branchRefPrefix))
+	err := filepath.WalkDir(headsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			name, _ := filepath.Rel(headsDir, path)
+			branches[filepath.ToSlash(name)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	packed, err := os.Open(filepath.Join(commonDir, "packed-refs"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		defer packed.Close()
+		scanner := bufio.NewScanner(packed)
+		for scanner.Scan() {
+			// Lines are "<sha> <ref>", with comments and peeled tags ("^<sha>")
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 2 && strings.HasPrefix(fields[1], branchRefPrefix) {
+				branches[strings.TrimPrefix(fields[1], branchRefPrefix)] = true
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(branches))
+	for name := range branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// gitDirAbove returns the git directory of the worktree containing dir
+func gitDirAbove(dir string) (string, bool) {
+	root, err := findGitRoot(dir)
+	if err != nil || root == "" {
+		return "", false
+	}
+	return gitDirOf(root)
+}
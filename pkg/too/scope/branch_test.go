@@ -0,0 +1,91 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentBranch(t *testing.T) {
+	newRepo := func(t *testing.T, head string) string {
+		t.Helper()
+		root := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte(head), 0644))
+		return root
+	}
+
+	t.Run("reads the branch from HEAD", func(t *testing.T) {
+		root := newRepo(t, "ref: refs/heads/feature/login\n")
+		subDir := filepath.Join(root, "src")
+		require.NoError(t, os.Mkdir(subDir, 0755))
+
+		assert.Equal(t, "feature/login", CurrentBranch(subDir))
+	})
+
+	t.Run("is empty on a detached HEAD", func(t *testing.T) {
+		root := newRepo(t, "0123456789abcdef0123456789abcdef01234567\n")
+		assert.Empty(t, CurrentBranch(root))
+	})
+
+	t.Run("is empty outside git", func(t *testing.T) {
+		assert.Empty(t, CurrentBranch(t.TempDir()))
+	})
+
+	t.Run("reads the HEAD of a linked worktree", func(t *testing.T) {
+		root := newRepo(t, "ref: refs/heads/main\n")
+		worktreeGitDir := filepath.Join(root, ".git", "worktrees", "feature")
+		require.NoError(t, os.MkdirAll(worktreeGitDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(worktreeGitDir, "HEAD"), []byte("ref: refs/heads/feature\n"), 0644))
+
+		linked := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(linked, ".git"), []byte("gitdir: "+worktreeGitDir+"\n"), 0644))
+
+		assert.Equal(t, "main", CurrentBranch(root))
+		assert.Equal(t, "feature", CurrentBranch(linked))
+	})
+}
+
+func TestLocalBranches(t *testing.T) {
+	t.Run("lists loose and packed branches", func(t *testing.T) {
+		root := t.TempDir()
+		heads := filepath.Join(root, ".git", "refs", "heads")
+		require.NoError(t, os.MkdirAll(filepath.Join(heads, "feature"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(heads, "main"), []byte("abc\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(heads, "feature", "login"), []byte("abc\n"), 0644))
+		packed := "# pack-refs with: peeled fully-peeled sorted\n" +
+			"abc refs/heads/main\n" +
+			"def refs/heads/old\n" +
+			"123 refs/tags/v1.0\n" +
+			"^456\n"
+		require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "packed-refs"), []byte(packed), 0644))
+
+		branches, err := LocalBranches(root)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"feature/login", "main", "old"}, branches)
+	})
+
+	t.Run("linked worktrees list the shared branches", func(t *testing.T) {
+		root := t.TempDir()
+		worktreeGitDir := filepath.Join(root, ".git", "worktrees", "feature")
+		require.NoError(t, os.MkdirAll(worktreeGitDir, 0755))
+		require.NoError(t, os.MkdirAll(filepath.Join(root, ".git", "refs", "heads"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "refs", "heads", "main"), []byte("abc\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(worktreeGitDir, "commondir"), []byte("../..\n"), 0644))
+
+		linked := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(linked, ".git"), []byte("gitdir: "+worktreeGitDir+"\n"), 0644))
+
+		branches, err := LocalBranches(linked)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"main"}, branches)
+	})
+
+	t.Run("fails outside git", func(t *testing.T) {
+		_, err := LocalBranches(t.TempDir())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,171 @@
+package scope
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// GlobalName names the global store wherever a project name is expected, as
+// in "g:2". No project is given the name.
+const GlobalName = "g"
+
+// reservedNames can't name projects: the global store's name and the
+// relations of symbolic references, as in "next:3"
+var reservedNames = map[string]bool{
+	GlobalName: true,
+	"parent":   true,
+	"first":    true,
+	"last":     true,
+	"next":     true,
+	"prev":     true,
+}
+
+// Project is a project store too has used
+type Project struct {
+	Name  string    `json:"name"`  // Short name, used to qualify references as in "api:3"
+	Root  string    `json:"root"`  // The project's git root
+	Path  string    `json:"path"`  // The store file
+	Added time.Time `json:"added"` // When too first used the store
+}
+
+// Registry lists the project stores too has used, so that they can be
+// listed and searched together
+type Registry struct {
+	path     string
+	Projects []*Project `json:"projects"`
+}
+
+// DefaultRegistryPath is where the registry is kept, under XDG data
+func DefaultRegistryPath() string {
+	return filepath.Join(getXDGDataHome(), "too", "projects.json")
+}
+
+// LoadRegistry reads the registry at path. A missing file is an empty
+// registry.
+func LoadRegistry(path string) (*Registry, error) {
+	registry := &Registry{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, registry); err != nil {
+		return nil, fmt.Errorf("failed to read project registry %s: %w", path, err)
+	}
+	return registry, nil
+}
+
+// Save writes the registry back to its file, replacing it in one step
+func (r *Registry) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Replace the file in one step, so a failed or concurrent save never
+	// leaves it half written
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// Add records the store of the project at root, naming it after the root's
+// directory. It returns the project and whether it is new.
+func (r *Registry) Add(root, storePath string) (*Project, bool) {
+	for _, project := range r.Projects {
+		if project.Path == storePath {
+			return project, false
+		}
+	}
+
+	project := &Project{
+		Name:  r.uniqueName(root),
+		Root:  root,
+		Path:  storePath,
+		Added: time.Now(),
+	}
+	r.Projects = append(r.Projects, project)
+	sort.Slice(r.Projects, func(i, j int) bool {
+		return r.Projects[i].Name < r.Projects[j].Name
+	})
+	return project, true
+}
+
+// Remove drops the project with the given store
+func (r *Registry) Remove(storePath string) {
+	for i, project := range r.Projects {
+		if project.Path == storePath {
+			r.Projects = append(r.Projects[:i], r.Projects[i+1:]...)
+			return
+		}
+	}
+}
+
+// Find returns the project called name
+func (r *Registry) Find(name string) (*Project, bool) {
+	for _, project := range r.Projects {
+		if project.Name == name {
+			return project, true
+		}
+	}
+	return nil, false
+}
+
+// uniqueName names a project after its directory, qualified with the
+// parent directory, then numbered, when another project has the name or it
+// is reserved
+func (r *Registry) uniqueName(root string) string {
+	base := filepath.Base(root)
+	candidates := []string{base, filepath.Base(filepath.Dir(root)) + "/" + base}
+	for _, name := range candidates {
+		if _, taken := r.Find(name); !taken && !reservedNames[name] {
+			return name
+		}
+	}
+	for n := 2; ; n++ {
+		name := fmt.Sprintf("%s-%d", base, n)
+		if _, taken := r.Find(name); !taken {
+			return name
+		}
+	}
+}
+
+// RecordProject adds a project scope's store to the registry at path. Other
+// scopes are ignored.
+func RecordProject(path string, scope *Scope) error {
+	if scope == nil || scope.IsGlobal || scope.GitRoot == "" {
+		return nil
+	}
+
+	registry, err := LoadRegistry(path)
+	if err != nil {
+		return err
+	}
+	if _, added := registry.Add(scope.GitRoot, scope.Path); !added {
+		return nil
+	}
+	return registry.Save()
+}
@@ -0,0 +1,97 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("a missing file is an empty registry", func(t *testing.T) {
+		registry, err := LoadRegistry(filepath.Join(t.TempDir(), "projects.json"))
+		require.NoError(t, err)
+		assert.Empty(t, registry.Projects)
+	})
+
+	t.Run("projects are saved sorted by name", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "too", "projects.json")
+		registry, err := LoadRegistry(path)
+		require.NoError(t, err)
+
+		web, added := registry.Add("/src/web", "/src/web/.todos.json")
+		assert.True(t, added)
+		assert.Equal(t, "web", web.Name)
+		registry.Add("/src/api", "/src/api/.todos.json")
+		require.NoError(t, registry.Save())
+
+		loaded, err := LoadRegistry(path)
+		require.NoError(t, err)
+		require.Len(t, loaded.Projects, 2)
+		assert.Equal(t, "api", loaded.Projects[0].Name)
+		assert.Equal(t, "/src/web/.todos.json", loaded.Projects[1].Path)
+
+		// Only the registry is left in its directory
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "projects.json", entries[0].Name())
+	})
+
+	t.Run("a store is added once", func(t *testing.T) {
+		registry := &Registry{}
+		first, _ := registry.Add("/src/api", "/src/api/.todos.json")
+		again, added := registry.Add("/src/api", "/src/api/.todos.json")
+		assert.False(t, added)
+		assert.Same(t, first, again)
+		assert.Len(t, registry.Projects, 1)
+	})
+
+	t.Run("clashing names are qualified, then numbered", func(t *testing.T) {
+		registry := &Registry{}
+		registry.Add("/work/api", "/work/api/.todos.json")
+		home, _ := registry.Add("/home/api", "/home/api/.todos.json")
+		other, _ := registry.Add("/other/home/api", "/other/home/api/.todos.json")
+
+		assert.Equal(t, "home/api", home.Name)
+		assert.Equal(t, "api-2", other.Name)
+		found, ok := registry.Find("home/api")
+		require.True(t, ok)
+		assert.Same(t, home, found)
+	})
+
+	t.Run("no project is named like the global store or a symbol", func(t *testing.T) {
+		registry := &Registry{}
+		for _, name := range []string{GlobalName, "parent", "first", "last", "next", "prev"} {
+			project, _ := registry.Add("/src/"+name, "/src/"+name+"/.todos.json")
+			assert.Equal(t, "src/"+name, project.Name)
+		}
+	})
+
+	t.Run("remove drops the store", func(t *testing.T) {
+		registry := &Registry{}
+		registry.Add("/src/api", "/src/api/.todos.json")
+		registry.Remove("/src/api/.todos.json")
+		_, ok := registry.Find("api")
+		assert.False(t, ok)
+	})
+}
+
+func TestRecordProject(t *testing.T) {
+	t.Run("records project scopes only", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "projects.json")
+
+		require.NoError(t, RecordProject(path, &Scope{IsGlobal: true, Path: "/home/u/.todos.json"}))
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+
+		require.NoError(t, RecordProject(path, &Scope{Path: "/src/api/.todos.json", GitRoot: "/src/api"}))
+		registry, err := LoadRegistry(path)
+		require.NoError(t, err)
+		require.Len(t, registry.Projects, 1)
+		assert.Equal(t, "api", registry.Projects[0].Name)
+		assert.Equal(t, "/src/api", registry.Projects[0].Root)
+	})
+}
@@ -1,15 +1,18 @@
 package scope
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Scope represents the current operation scope
 type Scope struct {
 	IsGlobal   bool
 	Path       string
-	GitRoot    string // Empty if global or not in git repo
+	GitRoot    string // The project root, empty if global. Not always a git repo, see Marker
+	Marker     string // What marks the project root: .too, .git, .jj, .hg, or the store itself in a sub-scope
 }
 
 // Resolver handles scope detection and resolution
@@ -37,8 +40,8 @@ func (r *Resolver) Resolve(currentDir string) (*Scope, error) {
 		}, nil
 	}
 
-	// Try to find git root
-	gitRoot, err := findGitRoot(currentDir)
+	// Try to find the project root
+	gitRoot, marker, err := findProjectRoot(currentDir)
 	if err != nil || gitRoot == "" {
 		// Not in a git repo, use global
 		globalPath := r.getGlobalPath()
@@ -49,15 +52,33 @@ func (r *Resolver) Resolve(currentDir string) (*Scope, error) {
 		}, nil
 	}
 
-	// In a git repo, use project scope
-	projectPath := filepath.Join(gitRoot, ".todos.json")
+	// Below the repository root, the nearest existing store starts a sub-scope
+	if subRoot := findSubScope(currentDir, gitRoot); subRoot != "" {
+		gitRoot, marker = subRoot, StoreFile
+	}
+
+	// Linked worktrees keep their own list unless the repository shares one
+	if marker == gitMarker {
+		if mainRoot, ok := sharedWorktreeRoot(gitRoot); ok {
+			gitRoot = mainRoot
+		}
+	}
+
+	// In a project, use project scope
+	projectPath := filepath.Join(gitRoot, StoreFile)
 	return &Scope{
 		IsGlobal: false,
 		Path:     projectPath,
 		GitRoot:  gitRoot,
+		Marker:   marker,
 	}, nil
 }
 
+// GlobalPath returns the global todos storage path
+func GlobalPath() string {
+	return NewResolver(true).getGlobalPath()
+}
+
 // getGlobalPath returns the global todos storage path
 func (r *Resolver) getGlobalPath() string {
 	return filepath.Join(r.xdgDataHome, "too", "todos.json")
@@ -77,30 +98,299 @@ func getXDGDataHome() string {
 	return filepath.Join(home, ".local", "share")
 }
 
-// findGitRoot finds the root of the git repository containing dir
+// StoreFile is the name of a project's store
+const StoreFile = ".todos.json"
+
+// Project root markers. gitMarker may be a directory, or a file pointing to
+// the git directory in linked worktrees and submodules. tooMarker marks
+// projects outside version control.
+const (
+	tooMarker = ".too"
+	gitMarker = ".git"
+)
+
+// projectMarkers are the entries marking a project root, in order of
+// preference when a directory has several
+var projectMarkers = []string{tooMarker, gitMarker, ".jj", ".hg"}
+
+// findProjectRoot finds the nearest directory above dir, dir included, with
+// one of the project markers. It returns the root and its marker.
+func findProjectRoot(dir string) (string, string, error) {
+	return findRoot(dir, projectMarkers)
+}
+
+// ProjectMarker returns what marks the root of the project containing dir,
+// looking past sub-scopes, or "" outside of projects
+func ProjectMarker(dir string) string {
+	root, marker, err := findProjectRoot(dir)
+	if err != nil || root == "" {
+		return ""
+	}
+	return marker
+}
+
+// findSubScope returns the nearest directory between dir and root, root
+// excluded, holding a store. Stores only start sub-scopes inside a
+// project, so that one in the home directory doesn't claim everything below.
+func findSubScope(dir, root string) string {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for current != root && strings.HasPrefix(current, root+string(filepath.Separator)) {
+		if info, err := os.Stat(filepath.Join(current, StoreFile)); err == nil && !info.IsDir() {
+			return current
+		}
+		current = filepath.Dir(current)
+	}
+	return ""
+}
+
+// FindSubScopes returns the stores in the directories below dir, dir
+// excluded, in path order. Hidden directories and node_modules are skipped.
+func FindSubScopes(dir string) ([]string, error) {
+	var stores []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped, not fatal
+			if entry != nil && entry.IsDir() && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			if path != dir && (strings.HasPrefix(entry.Name(), ".") || entry.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Name() == StoreFile && filepath.Dir(path) != dir {
+			stores = append(stores, path)
+		}
+		return nil
+	})
+	return stores, err
+}
+
+// findGitRoot finds the root of the git repository or worktree containing dir
 func findGitRoot(dir string) (string, error) {
+	root, _, err := findRoot(dir, []string{gitMarker})
+	return root, err
+}
+
+// findRoot finds the nearest directory above dir, dir included, with one of
+// markers, and returns it along with the marker found
+func findRoot(dir string, markers []string) (string, string, error) {
 	absDir, err := filepath.Abs(dir)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	current := absDir
 	for {
-		gitDir := filepath.Join(current, ".git")
-		if info, err := os.Stat(gitDir); err == nil && info.IsDir() {
-			return current, nil
+		for _, marker := range markers {
+			if hasMarker(current, marker) {
+				return current, marker, nil
+			}
 		}
 
 		parent := filepath.Dir(current)
 		if parent == current {
 			// Reached filesystem root
-			return "", nil
+			return "", "", nil
 		}
 		current = parent
 	}
 }
 
-// EnsureGitignore ensures .todos.json is in .gitignore when using project scope
+// hasMarker reports whether dir has the marker. A .git file only counts
+// when it points to a git directory, a .too marker may be a file or a
+// directory, the others are directories.
+func hasMarker(dir, marker string) bool {
+	info, err := os.Stat(filepath.Join(dir, marker))
+	if err != nil {
+		return false
+	}
+	switch {
+	case info.IsDir():
+		return true
+	case marker == tooMarker:
+		return true
+	case marker == gitMarker:
+		_, ok := gitDirOf(dir)
+		return ok
+	}
+	return false
+}
+
+// gitDirOf returns the git directory of the worktree at root: .git itself,
+// or the directory its "gitdir:" line points to
+func gitDirOf(root string) (string, bool) {
+	dotGit := filepath.Join(root, gitMarker)
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", false
+	}
+	if info.IsDir() {
+		return dotGit, true
+	}
+
+	content, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", false
+	}
+	line := strings.TrimSpace(strings.SplitN(string(content), "\n", 2)[0])
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", false
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(root, gitDir)
+	}
+	return filepath.Clean(gitDir), true
+}
+
+// sharedWorktreeRoot returns the main worktree's root when root is a linked
+// worktree of a repository configured to share one list between worktrees:
+//
+//	git config too.worktrees shared
+//
+// Submodules have a git directory of their own and are separate projects.
+func sharedWorktreeRoot(root string) (string, bool) {
+	gitDir, ok := gitDirOf(root)
+	if !ok {
+		return "", false
+	}
+
+	// Linked worktrees point to the repository's common directory
+	commonDir := commonDirOf(gitDir)
+	if commonDir == gitDir {
+		return "", false
+	}
+
+	if !strings.EqualFold(gitConfigValue(filepath.Join(commonDir, "config"), "too", "worktrees"), "shared") {
+		return "", false
+	}
+	// Bare repositories have no main worktree to share
+	if filepath.Base(commonDir) != gitMarker {
+		return "", false
+	}
+	return filepath.Dir(commonDir), true
+}
+
+// commonDirOf returns the directory holding the refs and config shared by
+// the worktrees of gitDir's repository: gitDir itself, unless it is a
+// linked worktree's
+func commonDirOf(gitDir string) string {
+	common, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	commonDir := strings.TrimSpace(string(common))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return filepath.Clean(commonDir)
+}
+
+// gitConfigValue reads key in section from the git config file at path. It
+// handles the plain "[section]" and "key = value" lines too needs, not
+// subsections or includes.
+func gitConfigValue(path, section, key string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	inSection := false
+	value := ""
+	for _, line := range splitLines(string(content)) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			name := strings.TrimSpace(strings.Trim(line, "[]"))
+			inSection = strings.EqualFold(name, section)
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		name, rest, found := strings.Cut(line, "=")
+		if found && strings.EqualFold(strings.TrimSpace(name), key) {
+			value = strings.Trim(strings.TrimSpace(rest), "\"")
+		}
+	}
+	return value
+}
+
+// setGitConfigValue sets key in section of the git config file at path,
+// replacing the key's value or adding the key, and the section, as needed.
+// Like gitConfigValue it handles plain sections and keys.
+func setGitConfigValue(path, section, key, value string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	lines := splitLines(string(content))
+	inSection := false
+	sectionEnd := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			name := strings.TrimSpace(strings.Trim(trimmed, "[]"))
+			inSection = strings.EqualFold(name, section)
+			if inSection {
+				sectionEnd = i
+			}
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		sectionEnd = i
+		name, _, found := strings.Cut(trimmed, "=")
+		if found && strings.EqualFold(strings.TrimSpace(name), key) {
+			lines[i] = "\t" + key + " = " + value
+			return writeLines(path, lines)
+		}
+	}
+
+	entry := "\t" + key + " = " + value
+	if sectionEnd < 0 {
+		lines = append(lines, "["+section+"]", entry)
+	} else {
+		lines = append(lines[:sectionEnd+1], append([]string{entry}, lines[sectionEnd+1:]...)...)
+	}
+	return writeLines(path, lines)
+}
+
+// writeLines writes lines to the file at path, each ended by a newline
+func writeLines(path string, lines []string) error {
+	var content strings.Builder
+	for _, line := range lines {
+		content.WriteString(line + "\n")
+	}
+	return os.WriteFile(path, []byte(content.String()), 0644)
+}
+
+// ignoredFiles are the files of a project store that stay out of git: the
+// store itself, the last view kept next to it and the archive of deleted
+// branches' todos, with its own view, and the locks shared stores are
+// written under
+var ignoredFiles = []string{
+	".todos.json", ".todos.json.view", ".todos.archive.json", ".todos.archive.json.view",
+	".todos.json.layout.lock", ".todos.archive.json.layout.lock",
+}
+
+// UsesGitignore reports whether the project at a root with marker follows
+// .gitignore files: git and jj projects do
+func UsesGitignore(marker string) bool {
+	return marker == gitMarker || marker == ".jj"
+}
+
+// EnsureGitignore ensures the project store's files are in .gitignore when
+// using project scope. In a shared repository (see IsShared) the store is
+// committed, so it is taken out of .gitignore instead.
 func EnsureGitignore(gitRoot string) error {
 	gitignorePath := filepath.Join(gitRoot, ".gitignore")
 	
@@ -110,20 +400,45 @@ func EnsureGitignore(gitRoot string) error {
 		return err
 	}
 
-	// Check if .todos.json is already in gitignore
-	lines := splitLines(string(content))
-	for _, line := range lines {
-		if line == ".todos.json" || line == "/.todos.json" {
-			// Already ignored
-			return nil
+	// Check which files are already in gitignore
+	shared := IsShared(gitRoot)
+	ignored := make(map[string]bool)
+	var kept []string
+	for _, line := range splitLines(string(content)) {
+		name := strings.TrimPrefix(line, "/")
+		if shared && name == StoreFile {
+			continue
+		}
+		ignored[name] = true
+		kept = append(kept, line)
+	}
+	unignored := len(kept) < len(splitLines(string(content)))
+	if unignored {
+		content = []byte(strings.Join(kept, "\n"))
+		if len(kept) > 0 {
+			content = append(content, '\n')
+		}
+	}
+
+	var missing []byte
+	for _, name := range ignoredFiles {
+		if !ignored[name] && !(shared && name == StoreFile) {
+			missing = append(missing, []byte(name+"\n")...)
+		}
+	}
+	if len(missing) == 0 {
+		if unignored {
+			return os.WriteFile(gitignorePath, content, 0644)
 		}
+		// Already ignored
+		return nil
 	}
 
-	// Add .todos.json to gitignore
+	// Add the missing files to gitignore
 	if len(content) > 0 && !endsWithNewline(content) {
 		content = append(content, '\n')
 	}
-	content = append(content, []byte(".todos.json\n")...)
+	content = append(content, missing...)
 
 	return os.WriteFile(gitignorePath, content, 0644)
 }
@@ -159,6 +159,16 @@ func TestFindGitRoot(t *testing.T) {
 		assert.Equal(t, tmpDir, root)
 	})
 
+	t.Run("finds worktrees with a .git file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tmpDir, ".git"), []byte("gitdir: /src/repo/.git/worktrees/a\n"), 0644)
+		require.NoError(t, err)
+
+		root, err := findGitRoot(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, tmpDir, root)
+	})
+
 	t.Run("returns empty when no git repo found", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		
@@ -193,6 +203,239 @@ func TestFindGitRoot(t *testing.T) {
 	})
 }
 
+func TestResolverProjectLayouts(t *testing.T) {
+	// newRepo creates a main worktree at root with a linked worktree at
+	// linked, the way "git worktree add" lays them out
+	newRepo := func(t *testing.T) (string, string) {
+		t.Helper()
+		base := t.TempDir()
+		root := filepath.Join(base, "repo")
+		require.NoError(t, os.MkdirAll(filepath.Join(root, ".git", "worktrees", "feature"), 0755))
+
+		linked := filepath.Join(base, "feature")
+		require.NoError(t, os.MkdirAll(linked, 0755))
+		worktreeGitDir := filepath.Join(root, ".git", "worktrees", "feature")
+		require.NoError(t, os.WriteFile(filepath.Join(linked, ".git"), []byte("gitdir: "+worktreeGitDir+"\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(worktreeGitDir, "commondir"), []byte("../..\n"), 0644))
+		return root, linked
+	}
+
+	resolve := func(t *testing.T, dir string) *Scope {
+		t.Helper()
+		scope, err := NewResolver(false).Resolve(dir)
+		require.NoError(t, err)
+		return scope
+	}
+
+	t.Run("linked worktrees have their own list", func(t *testing.T) {
+		_, linked := newRepo(t)
+		subDir := filepath.Join(linked, "src")
+		require.NoError(t, os.Mkdir(subDir, 0755))
+
+		scope := resolve(t, subDir)
+		assert.False(t, scope.IsGlobal)
+		assert.Equal(t, linked, scope.GitRoot)
+		assert.Equal(t, filepath.Join(linked, ".todos.json"), scope.Path)
+		assert.Equal(t, ".git", scope.Marker)
+	})
+
+	t.Run("linked worktrees share the main list when configured", func(t *testing.T) {
+		root, linked := newRepo(t)
+		config := "[core]\n\tbare = false\n[too]\n\tworktrees = shared\n"
+		require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "config"), []byte(config), 0644))
+
+		scope := resolve(t, linked)
+		assert.Equal(t, root, scope.GitRoot)
+		assert.Equal(t, filepath.Join(root, ".todos.json"), scope.Path)
+	})
+
+	t.Run("submodules are projects of their own", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, ".git", "modules", "lib"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "config"), []byte("[too]\n\tworktrees = shared\n"), 0644))
+		lib := filepath.Join(root, "lib")
+		require.NoError(t, os.Mkdir(lib, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(lib, ".git"), []byte("gitdir: ../.git/modules/lib\n"), 0644))
+
+		scope := resolve(t, lib)
+		assert.Equal(t, lib, scope.GitRoot)
+		assert.Equal(t, filepath.Join(lib, ".todos.json"), scope.Path)
+	})
+
+	t.Run("a .git file without gitdir is not a repository", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".git"), []byte("not a pointer\n"), 0644))
+
+		assert.True(t, resolve(t, dir).IsGlobal)
+	})
+
+	t.Run("a .too marker makes a project outside version control", func(t *testing.T) {
+		for _, asDir := range []bool{false, true} {
+			root := t.TempDir()
+			marker := filepath.Join(root, ".too")
+			if asDir {
+				require.NoError(t, os.Mkdir(marker, 0755))
+			} else {
+				require.NoError(t, os.WriteFile(marker, nil, 0644))
+			}
+			subDir := filepath.Join(root, "notes")
+			require.NoError(t, os.Mkdir(subDir, 0755))
+
+			scope := resolve(t, subDir)
+			assert.False(t, scope.IsGlobal)
+			assert.Equal(t, root, scope.GitRoot)
+			assert.Equal(t, ".too", scope.Marker)
+			assert.False(t, UsesGitignore(scope.Marker))
+		}
+	})
+
+	t.Run("a .too marker inside a repository starts a project", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+		docs := filepath.Join(root, "docs")
+		require.NoError(t, os.Mkdir(docs, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(docs, ".too"), nil, 0644))
+
+		assert.Equal(t, docs, resolve(t, docs).GitRoot)
+		assert.Equal(t, root, resolve(t, root).GitRoot)
+	})
+
+	t.Run("mercurial and jujutsu roots are projects", func(t *testing.T) {
+		for _, marker := range []string{".hg", ".jj"} {
+			root := t.TempDir()
+			require.NoError(t, os.Mkdir(filepath.Join(root, marker), 0755))
+
+			scope := resolve(t, root)
+			assert.False(t, scope.IsGlobal, marker)
+			assert.Equal(t, root, scope.GitRoot, marker)
+			assert.Equal(t, marker, scope.Marker)
+		}
+		assert.True(t, UsesGitignore(".jj"))
+		assert.False(t, UsesGitignore(".hg"))
+	})
+}
+
+func TestResolverSubScopes(t *testing.T) {
+	// newMonorepo creates a repository with stores at its root and in
+	// services/api
+	newMonorepo := func(t *testing.T) string {
+		t.Helper()
+		root := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+		for _, dir := range []string{"", "services/api", "services/web/src"} {
+			require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+		}
+		for _, dir := range []string{"", "services/api"} {
+			require.NoError(t, os.WriteFile(filepath.Join(root, dir, ".todos.json"), []byte("[]"), 0644))
+		}
+		return root
+	}
+
+	resolve := func(t *testing.T, dir string) *Scope {
+		t.Helper()
+		scope, err := NewResolver(false).Resolve(dir)
+		require.NoError(t, err)
+		return scope
+	}
+
+	t.Run("the nearest store below the root wins", func(t *testing.T) {
+		root := newMonorepo(t)
+		apiDir := filepath.Join(root, "services", "api")
+		nested := filepath.Join(apiDir, "internal")
+		require.NoError(t, os.Mkdir(nested, 0755))
+
+		scope := resolve(t, nested)
+		assert.Equal(t, apiDir, scope.GitRoot)
+		assert.Equal(t, filepath.Join(apiDir, ".todos.json"), scope.Path)
+		assert.Equal(t, ".todos.json", scope.Marker)
+	})
+
+	t.Run("directories without a store use the root's", func(t *testing.T) {
+		root := newMonorepo(t)
+
+		scope := resolve(t, filepath.Join(root, "services", "web", "src"))
+		assert.Equal(t, root, scope.GitRoot)
+		assert.Equal(t, ".git", scope.Marker)
+	})
+
+	t.Run("stores outside a project don't start scopes", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".todos.json"), []byte("[]"), 0644))
+
+		assert.True(t, resolve(t, dir).IsGlobal)
+	})
+
+	t.Run("finds the sub-scopes below a directory", func(t *testing.T) {
+		root := newMonorepo(t)
+		hidden := filepath.Join(root, ".cache", "x")
+		require.NoError(t, os.MkdirAll(hidden, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(hidden, ".todos.json"), []byte("[]"), 0644))
+		web := filepath.Join(root, "services", "web")
+		require.NoError(t, os.WriteFile(filepath.Join(web, ".todos.json"), []byte("[]"), 0644))
+
+		stores, err := FindSubScopes(root)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(root, "services", "api", ".todos.json"),
+			filepath.Join(web, ".todos.json"),
+		}, stores)
+
+		stores, err = FindSubScopes(web)
+		require.NoError(t, err)
+		assert.Empty(t, stores)
+	})
+}
+
+func TestGitConfigValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	config := `[core]
+	worktrees = no
+[too]
+	# comments are skipped
+	Worktrees = "shared"
+[other]
+	worktrees = separate
+`
+	require.NoError(t, os.WriteFile(path, []byte(config), 0644))
+
+	assert.Equal(t, "shared", gitConfigValue(path, "too", "worktrees"))
+	assert.Empty(t, gitConfigValue(path, "too", "missing"))
+	assert.Empty(t, gitConfigValue(filepath.Join(t.TempDir(), "none"), "too", "worktrees"))
+}
+
+func TestSetGitConfigValue(t *testing.T) {
+	t.Run("adds the section to a new file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, setGitConfigValue(path, "too", "shared", "true"))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "[too]\n\tshared = true\n", string(content))
+	})
+
+	t.Run("adds the key to its section", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config")
+		config := "[too]\n\tworktrees = shared\n[core]\n\tbare = false\n"
+		require.NoError(t, os.WriteFile(path, []byte(config), 0644))
+
+		require.NoError(t, setGitConfigValue(path, "too", "shared", "true"))
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "[too]\n\tworktrees = shared\n\tshared = true\n[core]\n\tbare = false\n", string(content))
+	})
+
+	t.Run("replaces the value", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(path, []byte("[too]\n\tShared = false\n"), 0644))
+
+		require.NoError(t, setGitConfigValue(path, "too", "shared", "true"))
+		assert.Equal(t, "true", gitConfigValue(path, "too", "shared"))
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "[too]\n\tshared = true\n", string(content))
+	})
+}
+
 func TestEnsureGitignore(t *testing.T) {
 	t.Run("adds to empty gitignore", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -243,7 +486,7 @@ func TestEnsureGitignore(t *testing.T) {
 		gitignorePath := filepath.Join(tmpDir, ".gitignore")
 		
 		// Create gitignore with .todos.json already
-		original := []byte("*.log\n.todos.json\n*.tmp\n")
+		original := []byte("*.log\n.todos.json\n*.tmp\n.todos.json.view\n.todos.archive.json\n.todos.archive.json.view\n.todos.json.layout.lock\n.todos.archive.json.layout.lock\n")
 		err := os.WriteFile(gitignorePath, original, 0644)
 		require.NoError(t, err)
 
@@ -260,7 +503,7 @@ func TestEnsureGitignore(t *testing.T) {
 		gitignorePath := filepath.Join(tmpDir, ".gitignore")
 		
 		// Create gitignore with /.todos.json pattern
-		original := []byte("*.log\n/.todos.json\n*.tmp\n")
+		original := []byte("*.log\n/.todos.json\n*.tmp\n/.todos.json.view\n/.todos.archive.json\n/.todos.archive.json.view\n/.todos.json.layout.lock\n/.todos.archive.json.layout.lock\n")
 		err := os.WriteFile(gitignorePath, original, 0644)
 		require.NoError(t, err)
 
@@ -271,4 +514,35 @@ func TestEnsureGitignore(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, string(original), string(content))
 	})
+
+	t.Run("adds the last view and archive to an ignored store", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		gitignorePath := filepath.Join(tmpDir, ".gitignore")
+		
+		err := os.WriteFile(gitignorePath, []byte(".todos.json\n"), 0644)
+		require.NoError(t, err)
+
+		err = EnsureGitignore(tmpDir)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(gitignorePath)
+		require.NoError(t, err)
+		assert.Equal(t, ".todos.json\n.todos.json.view\n.todos.archive.json\n.todos.archive.json.view\n.todos.json.layout.lock\n.todos.archive.json.layout.lock\n", string(content))
+	})
+
+	t.Run("takes the store out in a shared repository", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(tmpDir, ".git"), 0755))
+		_, err := SetShared(tmpDir)
+		require.NoError(t, err)
+		gitignorePath := filepath.Join(tmpDir, ".gitignore")
+		require.NoError(t, os.WriteFile(gitignorePath, []byte("*.log\n/.todos.json\n.todos.json.view\n"), 0644))
+
+		err = EnsureGitignore(tmpDir)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(gitignorePath)
+		require.NoError(t, err)
+		assert.Equal(t, "*.log\n.todos.json.view\n.todos.archive.json\n.todos.archive.json.view\n.todos.json.layout.lock\n.todos.archive.json.layout.lock\n", string(content))
+	})
 }
\ No newline at end of file
@@ -0,0 +1,128 @@
+package scope
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Merge driver settings, see InstallMergeDriver
+const (
+	mergeDriverSection = `merge "too"`
+	mergeDriverCommand = "too merge-driver %O %A %B"
+	mergeAttribute     = StoreFile + " merge=too"
+)
+
+// sharedAttribute marks the stores of a repository as committed, see
+// SetShared
+const sharedAttribute = StoreFile + " too-shared"
+
+// IsShared reports whether the git repository containing dir keeps its
+// todos in git, set up by SetShared with this line in the .gitattributes
+// at the root of the worktree:
+//
+//	.todos.json too-shared
+//
+// Shared stores are left out of .gitignore and written in a layout meant
+// for diffs.
+func IsShared(dir string) bool {
+	root, err := findGitRoot(dir)
+	if err != nil || root == "" {
+		return false
+	}
+	found, _ := hasAttribute(root, sharedAttribute)
+	return found
+}
+
+// SetShared records in the .gitattributes of the git repository containing
+// dir that its todos are committed, and returns the repository's root. The
+// file is meant to be committed, so that every clone shares the setting.
+func SetShared(dir string) (string, error) {
+	root, err := findGitRoot(dir)
+	if err != nil {
+		return "", err
+	}
+	if root == "" {
+		return "", fmt.Errorf("%s is not in a git repository", dir)
+	}
+	if err := addAttribute(root, sharedAttribute); err != nil {
+		return "", err
+	}
+	return root, nil
+}
+
+// InstallMergeDriver registers "too merge-driver" with the git repository
+// containing dir, for the stores of all its directories, and returns the
+// repository's root. The driver goes in the repository's config, which
+// every clone needs, and the attribute in .gitattributes, to commit.
+func InstallMergeDriver(dir string) (string, error) {
+	root, configPath, err := repositoryConfig(dir)
+	if err != nil {
+		return "", err
+	}
+	if err := setGitConfigValue(configPath, mergeDriverSection, "name", "too todo lists"); err != nil {
+		return "", fmt.Errorf("failed to update the git config: %w", err)
+	}
+	if err := setGitConfigValue(configPath, mergeDriverSection, "driver", mergeDriverCommand); err != nil {
+		return "", fmt.Errorf("failed to update the git config: %w", err)
+	}
+
+	if err := addAttribute(root, mergeAttribute); err != nil {
+		return "", err
+	}
+	return root, nil
+}
+
+// hasAttribute reports whether the .gitattributes file in root has line,
+// however it is spaced
+func hasAttribute(root, line string) (bool, error) {
+	content, err := os.ReadFile(filepath.Join(root, ".gitattributes"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, existing := range splitLines(string(content)) {
+		if strings.Join(strings.Fields(existing), " ") == line {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// addAttribute adds line to the .gitattributes file in root, unless it is
+// there already
+func addAttribute(root, line string) error {
+	if found, err := hasAttribute(root, line); err != nil || found {
+		return err
+	}
+	attributesPath := filepath.Join(root, ".gitattributes")
+	content, err := os.ReadFile(attributesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(content) > 0 && !endsWithNewline(content) {
+		content = append(content, '\n')
+	}
+	content = append(content, line+"\n"...)
+	if err := os.WriteFile(attributesPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to update .gitattributes: %w", err)
+	}
+	return nil
+}
+
+// repositoryConfig returns the root of the git worktree containing dir and
+// the path of its repository's config file
+func repositoryConfig(dir string) (string, string, error) {
+	root, err := findGitRoot(dir)
+	if err != nil {
+		return "", "", err
+	}
+	gitDir, ok := gitDirAbove(dir)
+	if root == "" || !ok {
+		return "", "", fmt.Errorf("%s is not in a git repository", dir)
+	}
+	return root, filepath.Join(commonDirOf(gitDir), "config"), nil
+}
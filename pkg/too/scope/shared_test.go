@@ -0,0 +1,86 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShared(t *testing.T) {
+	t.Run("is remembered in the committed .gitattributes", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+		subDir := filepath.Join(root, "src")
+		require.NoError(t, os.Mkdir(subDir, 0755))
+		attributesPath := filepath.Join(root, ".gitattributes")
+		require.NoError(t, os.WriteFile(attributesPath, []byte("*.png binary"), 0644))
+		assert.False(t, IsShared(subDir))
+
+		for i := 0; i < 2; i++ {
+			sharedRoot, err := SetShared(subDir)
+			require.NoError(t, err)
+			assert.Equal(t, root, sharedRoot)
+		}
+		assert.True(t, IsShared(root))
+		assert.True(t, IsShared(subDir))
+		attributes, err := os.ReadFile(attributesPath)
+		require.NoError(t, err)
+		assert.Equal(t, "*.png binary\n.todos.json too-shared\n", string(attributes))
+		assert.NoFileExists(t, filepath.Join(root, ".git", "config"))
+	})
+
+	t.Run("a clone without local config shares it", func(t *testing.T) {
+		origin := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(origin, ".git"), 0755))
+		_, err := SetShared(origin)
+		require.NoError(t, err)
+
+		// The clone has its own .git and a checkout of the committed files
+		clone := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(clone, ".git"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(clone, ".git", "config"), []byte("[core]\n\tbare = false\n"), 0644))
+		attributes, err := os.ReadFile(filepath.Join(origin, ".gitattributes"))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(clone, ".gitattributes"), attributes, 0644))
+
+		assert.True(t, IsShared(clone))
+		require.NoError(t, EnsureGitignore(clone))
+		ignored, err := os.ReadFile(filepath.Join(clone, ".gitignore"))
+		require.NoError(t, err)
+		assert.NotContains(t, splitLines(string(ignored)), StoreFile)
+	})
+
+	t.Run("needs git", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := SetShared(dir)
+		assert.Error(t, err)
+		assert.False(t, IsShared(dir))
+		assert.NoFileExists(t, filepath.Join(dir, ".gitattributes"))
+	})
+}
+
+func TestInstallMergeDriver(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+	attributesPath := filepath.Join(root, ".gitattributes")
+	require.NoError(t, os.WriteFile(attributesPath, []byte("*.png binary"), 0644))
+
+	for i := 0; i < 2; i++ {
+		installedRoot, err := InstallMergeDriver(root)
+		require.NoError(t, err)
+		assert.Equal(t, root, installedRoot)
+	}
+
+	configPath := filepath.Join(root, ".git", "config")
+	assert.Equal(t, "too merge-driver %O %A %B", gitConfigValue(configPath, `merge "too"`, "driver"))
+	assert.NotEmpty(t, gitConfigValue(configPath, `merge "too"`, "name"))
+	attributes, err := os.ReadFile(attributesPath)
+	require.NoError(t, err)
+	assert.Equal(t, "*.png binary\n.todos.json merge=too\n", string(attributes))
+
+	_, err = InstallMergeDriver(t.TempDir())
+	assert.Error(t, err)
+}
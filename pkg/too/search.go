@@ -0,0 +1,244 @@
+package too
+
+import (
+	"regexp"
+	"sort"
+	"unicode"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// SearchMode selects how a search query is matched against todos
+type SearchMode string
+
+const (
+	// SearchSubstring matches the query as plain text
+	SearchSubstring SearchMode = "substring"
+	// SearchRegex matches the query as a regular expression
+	SearchRegex SearchMode = "regex"
+	// SearchFuzzy matches todos containing the query's characters in order,
+	// ranking closer matches first
+	SearchFuzzy SearchMode = "fuzzy"
+)
+
+// Fields a search can look in
+const (
+	SearchInText  = "text"
+	SearchInNotes = "notes"
+)
+
+// SearchOptions describes a search
+type SearchOptions struct {
+	Query         string
+	Mode          SearchMode // Defaults to SearchSubstring
+	In            string     // SearchInText (default) or SearchInNotes
+	CaseSensitive bool
+	ShowAll       bool // Match done todos as well as pending ones
+}
+
+// Span is a matched byte range [Start, End) of a todo's text or notes
+type Span struct {
+	Start int `json:"start" yaml:"start"`
+	End   int `json:"end" yaml:"end"`
+}
+
+// SearchMatch is a todo found by a search
+type SearchMatch struct {
+	UID   string
+	Field string // SearchInText or SearchInNotes
+	Spans []Span
+	Score int // Higher is closer, only fuzzy searches rank matches
+}
+
+// matcher finds the query in s, returning the matched spans and a score
+type matcher func(s string) ([]Span, int, bool)
+
+// newMatcher builds the matcher for opts
+func newMatcher(opts SearchOptions) (matcher, error) {
+	switch opts.Mode {
+	case "", SearchSubstring, SearchRegex:
+		pattern := opts.Query
+		if opts.Mode != SearchRegex {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		if !opts.CaseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, NewValidationError("invalid regular expression %q: %v", opts.Query, err)
+		}
+		return regexMatcher(re), nil
+	case SearchFuzzy:
+		query := []rune(opts.Query)
+		return func(s string) ([]Span, int, bool) {
+			return fuzzyMatch(query, s, opts.CaseSensitive)
+		}, nil
+	default:
+		return nil, NewValidationError("unknown search mode: %s", opts.Mode)
+	}
+}
+
+// regexMatcher matches every non-empty occurrence of re
+func regexMatcher(re *regexp.Regexp) matcher {
+	return func(s string) ([]Span, int, bool) {
+		var spans []Span
+		for _, loc := range re.FindAllStringIndex(s, -1) {
+			if loc[1] > loc[0] {
+				spans = append(spans, Span{Start: loc[0], End: loc[1]})
+			}
+		}
+		return spans, 0, len(spans) > 0
+	}
+}
+
+// Fuzzy scoring: every matched character scores, with bonuses for runs of
+// consecutive characters and for characters starting a word, and a penalty
+// for the characters skipped between the first and last match
+const (
+	fuzzyCharScore        = 1
+	fuzzyConsecutiveBonus = 4
+	fuzzyWordStartBonus   = 6
+	fuzzyGapPenalty       = 1
+)
+
+// fuzzyMatch finds query's characters in s, in order. It takes the leftmost
+// match, then walks back from its end to the latest start so the matched
+// characters are as close together as possible.
+func fuzzyMatch(query []rune, s string, caseSensitive bool) ([]Span, int, bool) {
+	if len(query) == 0 {
+		return nil, 0, false
+	}
+
+	fold := func(r rune) rune {
+		if caseSensitive {
+			return r
+		}
+		return unicode.ToLower(r)
+	}
+
+	// Byte offset of every rune, plus the end of the string
+	runes := []rune(s)
+	offsets := make([]int, 0, len(runes)+1)
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(s))
+
+	// Forward pass: leftmost end of a match
+	q := 0
+	end := -1
+	for i, r := range runes {
+		if fold(r) == fold(query[q]) {
+			q++
+			if q == len(query) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return nil, 0, false
+	}
+
+	// Backward pass: latest positions that still match the whole query
+	positions := make([]int, len(query))
+	q = len(query) - 1
+	for i := end; i >= 0 && q >= 0; i-- {
+		if fold(runes[i]) == fold(query[q]) {
+			positions[q] = i
+			q--
+		}
+	}
+
+	score := 0
+	var spans []Span
+	for n, i := range positions {
+		score += fuzzyCharScore
+		if i == 0 || !isWordRune(runes[i-1]) {
+			score += fuzzyWordStartBonus
+		}
+		if n > 0 && positions[n-1] == i-1 {
+			score += fuzzyConsecutiveBonus
+			spans[len(spans)-1].End = offsets[i+1]
+			continue
+		}
+		spans = append(spans, Span{Start: offsets[i], End: offsets[i+1]})
+	}
+	score -= fuzzyGapPenalty * (positions[len(positions)-1] - positions[0] + 1 - len(positions))
+
+	return spans, score, true
+}
+
+// isWordRune reports whether r is part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// SearchTodos finds the todos matching opts. Matches come best first for
+// fuzzy searches and in list order otherwise. The returned todos hold every
+// match preceded by its ancestors, so they can be shown in their hierarchy,
+// ordered so that each subtree comes where its best match ranks.
+func (e *NanoEngine) SearchTodos(opts SearchOptions) ([]*models.Todo, []SearchMatch, error) {
+	field := opts.In
+	if field == "" {
+		field = SearchInText
+	}
+	if field != SearchInText && field != SearchInNotes {
+		return nil, nil, NewValidationError("can't search in %q, use %s or %s", field, SearchInText, SearchInNotes)
+	}
+
+	match, err := newMatcher(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	all, err := e.adapter.List(true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var matches []SearchMatch
+	for _, todo := range all {
+		if !opts.ShowAll && todo.GetStatus() == models.StatusDone {
+			continue
+		}
+		value := todo.Text
+		if field == SearchInNotes {
+			value = todo.Notes
+		}
+		if spans, score, ok := match(value); ok {
+			matches = append(matches, SearchMatch{UID: todo.UID, Field: field, Spans: spans, Score: score})
+		}
+	}
+	if opts.Mode == SearchFuzzy {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].Score > matches[j].Score
+		})
+	}
+
+	return withAncestors(all, matches), matches, nil
+}
+
+// withAncestors lists the matched todos, each preceded by the ancestors not
+// listed yet
+func withAncestors(all []*models.Todo, matches []SearchMatch) []*models.Todo {
+	byUID := make(map[string]*models.Todo, len(all))
+	for _, todo := range all {
+		byUID[todo.UID] = todo
+	}
+
+	listed := make(map[string]bool)
+	var todos []*models.Todo
+	for _, m := range matches {
+		var chain []*models.Todo
+		for todo := byUID[m.UID]; todo != nil && !listed[todo.UID]; todo = byUID[todo.ParentID] {
+			chain = append(chain, todo)
+			listed[todo.UID] = true
+		}
+		for i := len(chain) - 1; i >= 0; i-- {
+			todos = append(todos, chain[i])
+		}
+	}
+	return todos
+}
@@ -0,0 +1,130 @@
+package too
+
+import (
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	t.Run("matches letters in order", func(t *testing.T) {
+		spans, _, ok := fuzzyMatch([]rune("bmk"), "Buy milk", false)
+		require.True(t, ok)
+		assert.Equal(t, []Span{{0, 1}, {4, 5}, {7, 8}}, spans)
+
+		_, _, ok = fuzzyMatch([]rune("kb"), "Buy milk", false)
+		assert.False(t, ok)
+	})
+
+	t.Run("tightens the match and merges runs", func(t *testing.T) {
+		// The leftmost "m" is skipped for the one next to "ilk"
+		spans, _, ok := fuzzyMatch([]rune("milk"), "mom's milk", false)
+		require.True(t, ok)
+		assert.Equal(t, []Span{{6, 10}}, spans)
+	})
+
+	t.Run("ranks closer matches higher", func(t *testing.T) {
+		_, close, _ := fuzzyMatch([]rune("milk"), "Buy milk", false)
+		_, spread, _ := fuzzyMatch([]rune("milk"), "Email the lawyer about kites", false)
+		assert.Greater(t, close, spread)
+	})
+
+	t.Run("respects case when asked", func(t *testing.T) {
+		_, _, ok := fuzzyMatch([]rune("BM"), "Buy milk", true)
+		assert.False(t, ok)
+	})
+
+	t.Run("spans are byte offsets", func(t *testing.T) {
+		spans, _, ok := fuzzyMatch([]rune("café"), "Buy café beans", false)
+		require.True(t, ok)
+		assert.Equal(t, []Span{{4, 9}}, spans)
+	})
+}
+
+func TestSearchTodos(t *testing.T) {
+	adapter, dbPath := testutil.CreateStoreWithSpecs(t,
+		testutil.TodoSpec{Text: "Groceries"},
+		testutil.TodoSpec{Text: "Buy milk", ParentPos: "1"},
+		testutil.TodoSpec{Text: "Book flights", Notes: "Ask about the invoice\nwindow seat"},
+		testutil.TodoSpec{Text: "Bake bread", Complete: true},
+	)
+	defer adapter.Close()
+
+	engine, err := NewNanoEngine(dbPath)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	texts := func(t *testing.T, opts SearchOptions) ([]string, []SearchMatch) {
+		t.Helper()
+		todos, matches, err := engine.SearchTodos(opts)
+		require.NoError(t, err)
+		var result []string
+		for _, todo := range todos {
+			result = append(result, todo.Text)
+		}
+		return result, matches
+	}
+
+	t.Run("substring matches come with their ancestors", func(t *testing.T) {
+		todos, matches := texts(t, SearchOptions{Query: "MILK"})
+		assert.Equal(t, []string{"Groceries", "Buy milk"}, todos)
+		require.Len(t, matches, 1)
+		assert.Equal(t, SearchInText, matches[0].Field)
+		assert.Equal(t, []Span{{4, 8}}, matches[0].Spans)
+	})
+
+	t.Run("case sensitive", func(t *testing.T) {
+		todos, _ := texts(t, SearchOptions{Query: "MILK", CaseSensitive: true})
+		assert.Empty(t, todos)
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		todos, matches := texts(t, SearchOptions{Query: `^b\w+ (milk|flights)$`, Mode: SearchRegex})
+		assert.Equal(t, []string{"Groceries", "Buy milk", "Book flights"}, todos)
+		assert.Len(t, matches, 2)
+
+		_, _, err := engine.SearchTodos(SearchOptions{Query: "(", Mode: SearchRegex})
+		assert.Equal(t, ErrValidation, KindOf(err))
+	})
+
+	t.Run("fuzzy ranks best first", func(t *testing.T) {
+		todos, matches := texts(t, SearchOptions{Query: "bk", Mode: SearchFuzzy})
+		assert.Equal(t, []string{"Book flights", "Groceries", "Buy milk"}, todos)
+		require.Len(t, matches, 2)
+		assert.Greater(t, matches[0].Score, matches[1].Score)
+	})
+
+	t.Run("done todos only with show all", func(t *testing.T) {
+		todos, _ := texts(t, SearchOptions{Query: "bread"})
+		assert.Empty(t, todos)
+
+		todos, _ = texts(t, SearchOptions{Query: "bread", ShowAll: true})
+		assert.Equal(t, []string{"Bake bread"}, todos)
+	})
+
+	t.Run("notes", func(t *testing.T) {
+		todos, matches := texts(t, SearchOptions{Query: "invoice", In: SearchInNotes})
+		assert.Equal(t, []string{"Book flights"}, todos)
+		require.Len(t, matches, 1)
+		assert.Equal(t, SearchInNotes, matches[0].Field)
+
+		todos, _ = texts(t, SearchOptions{Query: "invoice"})
+		assert.Empty(t, todos)
+
+		_, _, err := engine.SearchTodos(SearchOptions{Query: "x", In: "tags"})
+		assert.Equal(t, ErrValidation, KindOf(err))
+	})
+
+	t.Run("through the search command", func(t *testing.T) {
+		result, err := ExecuteUnifiedCommandWithEngine(engine, "search", []string{"milk"}, map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, "Found 1 match", result.Message)
+		assert.Len(t, result.AllTodos, 2)
+		assert.Len(t, result.Matches, 1)
+
+		_, err = ExecuteUnifiedCommandWithEngine(engine, "search", []string{"milk"}, map[string]interface{}{"regex": true, "fuzzy": true})
+		assert.Equal(t, ErrValidation, KindOf(err))
+	})
+}
@@ -0,0 +1,261 @@
+// Package server exposes too's commands over a local HTTP/JSON API so
+// editors and dashboards can drive a collection without spawning a process
+// per action.
+//
+// Every endpoint runs a unified command against a single engine held open
+// for the life of the server, and responds with exactly the JSON that
+// `too <command> --format json` prints.
+//
+//	GET    /todos                   list (?done=true, ?all=true)
+//	GET    /search?q=<query>        search (?all=true)
+//	POST   /todos                   add {"text": "...", "parent": "1"}
+//	POST   /todos/{ref}/complete    complete
+//	POST   /todos/{ref}/reopen      reopen
+//	PATCH  /todos/{ref}             edit {"text": "..."}
+//	POST   /todos/{ref}/move        move {"to": "2"}
+//	POST   /clean                   clean
+//
+// Failures answer with the `--format json` error object and a status that
+// follows its kind: 404 for unknown references, 409 for ambiguous ones, 423
+// when the store is locked and 400 otherwise.
+//
+// Web pages open in the user's browser can reach the server too, so it only
+// answers requests addressed to the host it listens on, from no origin or
+// its own, and requests that change todos must be sent as
+// application/json, which pages can't do across origins without asking.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/arthur-debert/too/pkg/lipbalm"
+	"github.com/arthur-debert/too/pkg/logging"
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/output"
+	"github.com/rs/zerolog"
+)
+
+// Server serves the API for one collection
+type Server struct {
+	engine   *too.NanoEngine
+	renderer *lipbalm.RenderEngine
+	mux      *http.ServeMux
+	logger   zerolog.Logger
+	hosts    map[string]bool // Hosts requests may be addressed to
+
+	// mu serializes commands, the engine is not safe for concurrent use
+	mu sync.Mutex
+}
+
+// New creates a server that runs commands against the given engine, for
+// requests addressed to addr, the address it listens on. The caller owns the
+// engine and closes it once the server is done.
+func New(engine *too.NanoEngine, addr string) (*Server, error) {
+	outputEngine, err := output.NewEngine()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create render engine: %w", err)
+	}
+
+	s := &Server{
+		engine:   engine,
+		renderer: outputEngine.GetLipbalmEngine(),
+		mux:      http.NewServeMux(),
+		logger:   logging.GetLogger("too.server"),
+		hosts:    allowedHosts(addr),
+	}
+
+	s.mux.HandleFunc("GET /todos", s.handleList)
+	s.mux.HandleFunc("GET /search", s.handleSearch)
+	s.mux.HandleFunc("POST /todos", s.handleAdd)
+	s.mux.HandleFunc("POST /todos/{ref}/complete", s.handleStatus("complete"))
+	s.mux.HandleFunc("POST /todos/{ref}/reopen", s.handleStatus("reopen"))
+	s.mux.HandleFunc("PATCH /todos/{ref}", s.handleEdit)
+	s.mux.HandleFunc("POST /todos/{ref}/move", s.handleMove)
+	s.mux.HandleFunc("POST /clean", s.handleClean)
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.logger.Debug().Str("method", r.Method).Str("path", r.URL.Path).Msg("request")
+	if !s.hosts[r.Host] {
+		s.fail(w, http.StatusForbidden, fmt.Errorf("requests must be addressed to the server's own host, not '%s'", r.Host))
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !s.hosts[strings.TrimPrefix(origin, "http://")] {
+		s.fail(w, http.StatusForbidden, fmt.Errorf("requests from '%s' are not allowed", origin))
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			s.fail(w, http.StatusUnsupportedMediaType, fmt.Errorf("requests that change todos must be sent as application/json"))
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// allowedHosts returns the hosts requests to a server listening on addr may
+// name: addr itself and, on a loopback address, the other names of the
+// loopback interface with the same port. Listening on all interfaces, the
+// addresses of each are allowed as well.
+func allowedHosts(addr string) map[string]bool {
+	hosts := map[string]bool{addr: true}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return hosts
+	}
+	ip := net.ParseIP(host)
+	if host == "localhost" || (ip != nil && (ip.IsLoopback() || ip.IsUnspecified())) {
+		for _, name := range []string{"localhost", "127.0.0.1", "::1"} {
+			hosts[net.JoinHostPort(name, port)] = true
+		}
+	}
+	if host == "" || (ip != nil && ip.IsUnspecified()) {
+		interfaceAddrs, _ := net.InterfaceAddrs()
+		for _, interfaceAddr := range interfaceAddrs {
+			if ipNet, ok := interfaceAddr.(*net.IPNet); ok {
+				hosts[net.JoinHostPort(ipNet.IP.String(), port)] = true
+			}
+		}
+	}
+	return hosts
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.run(w, "list", nil, map[string]interface{}{
+		"done": queryBool(r, "done"),
+		"all":  queryBool(r, "all"),
+	})
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("search requires a query, use ?q="))
+		return
+	}
+	s.run(w, "search", []string{query}, map[string]interface{}{
+		"all": queryBool(r, "all"),
+	})
+}
+
+func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Text   string `json:"text"`
+		Parent string `json:"parent"`
+	}
+	if !s.decode(w, r, &body) {
+		return
+	}
+
+	opts := map[string]interface{}{}
+	if body.Parent != "" {
+		opts["parent"] = body.Parent
+	}
+	s.run(w, "add", []string{body.Text}, opts)
+}
+
+func (s *Server) handleStatus(command string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.run(w, command, []string{r.PathValue("ref")}, map[string]interface{}{})
+	}
+}
+
+func (s *Server) handleEdit(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Text string `json:"text"`
+	}
+	if !s.decode(w, r, &body) {
+		return
+	}
+	if body.Text == "" {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("edit requires the new text"))
+		return
+	}
+	s.run(w, "edit", []string{r.PathValue("ref"), body.Text}, map[string]interface{}{})
+}
+
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		To string `json:"to"`
+	}
+	if !s.decode(w, r, &body) {
+		return
+	}
+	if body.To == "" {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("move requires a destination"))
+		return
+	}
+	s.run(w, "move", []string{r.PathValue("ref"), body.To}, map[string]interface{}{})
+}
+
+func (s *Server) handleClean(w http.ResponseWriter, r *http.Request) {
+	s.run(w, "clean", nil, map[string]interface{}{})
+}
+
+// run executes a unified command and writes its result as JSON
+func (s *Server) run(w http.ResponseWriter, command string, args []string, opts map[string]interface{}) {
+	s.mu.Lock()
+	result, err := too.ExecuteUnifiedCommandWithEngine(s.engine, command, args, opts)
+	s.mu.Unlock()
+
+	if err != nil {
+		s.fail(w, errorStatus(err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := s.renderer.Render(w, "json", result); err != nil {
+		s.logger.Error().Err(err).Str("command", command).Msg("failed to render result")
+	}
+}
+
+// fail writes an error in the same shape as `--format json` errors
+func (s *Server) fail(w http.ResponseWriter, status int, err error) {
+	s.logger.Debug().Err(err).Int("status", status).Msg("request failed")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if renderErr := s.renderer.RenderError(w, "json", err); renderErr != nil {
+		s.logger.Error().Err(renderErr).Msg("failed to render error")
+	}
+}
+
+// errorStatus picks the HTTP status for a command error
+func errorStatus(err error) int {
+	switch too.KindOf(err) {
+	case too.ErrNotFound:
+		return http.StatusNotFound
+	case too.ErrAmbiguous:
+		return http.StatusConflict
+	case too.ErrStoreLocked:
+		return http.StatusLocked
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// decode reads a JSON request body, answering with an error when it is invalid
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+// queryBool reads a boolean query parameter, treating anything unparsable
+// as false
+func queryBool(r *http.Request, name string) bool {
+	value, _ := strconv.ParseBool(r.URL.Query().Get(name))
+	return value
+}
@@ -0,0 +1,222 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/output"
+	"github.com/arthur-debert/too/pkg/too/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonResult is the part of a rendered result the tests look at
+type jsonResult struct {
+	Command  string                  `json:"command"`
+	Message  string                  `json:"message"`
+	Affected []struct{ Text string } `json:"affected"`
+	Counts   struct {
+		Total int `json:"total"`
+		Done  int `json:"done"`
+		Shown int `json:"shown"`
+	} `json:"counts"`
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "test.json")
+	engine, err := too.NewNanoEngine(dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = engine.Close() })
+
+	ts := httptest.NewUnstartedServer(nil)
+	srv, err := server.New(engine, ts.Listener.Addr().String())
+	require.NoError(t, err)
+	ts.Config.Handler = srv
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts, dbPath
+}
+
+func do(t *testing.T, ts *httptest.Server, method, path, body string) (int, jsonResult, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if method != http.MethodGet {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return send(t, req)
+}
+
+// send makes the request and reads the response like do
+func send(t *testing.T, req *http.Request) (int, jsonResult, string) {
+	t.Helper()
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(resp.Body)
+	require.NoError(t, err)
+
+	var result jsonResult
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &result), buf.String())
+	}
+	return resp.StatusCode, result, buf.String()
+}
+
+func TestServer(t *testing.T) {
+	t.Run("runs the full command set against one engine", func(t *testing.T) {
+		ts, _ := newTestServer(t)
+
+		status, result, _ := do(t, ts, http.MethodPost, "/todos", `{"text": "Groceries"}`)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "add", result.Command)
+		require.Len(t, result.Affected, 1)
+		assert.Equal(t, "Groceries", result.Affected[0].Text)
+
+		status, _, _ = do(t, ts, http.MethodPost, "/todos", `{"text": "Milk", "parent": "1"}`)
+		require.Equal(t, http.StatusOK, status)
+		status, _, _ = do(t, ts, http.MethodPost, "/todos", `{"text": "Call mom"}`)
+		require.Equal(t, http.StatusOK, status)
+
+		status, result, _ = do(t, ts, http.MethodPatch, "/todos/1.1", `{"text": "Oat milk"}`)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "Oat milk", result.Affected[0].Text)
+
+		status, result, _ = do(t, ts, http.MethodPost, "/todos/1.1/move", `{"to": "2"}`)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "move", result.Command)
+
+		status, result, _ = do(t, ts, http.MethodGet, "/search?q=oat", "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "Found 1 match", result.Message)
+
+		status, result, _ = do(t, ts, http.MethodPost, "/todos/1/complete", "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 1, result.Counts.Done)
+
+		status, result, _ = do(t, ts, http.MethodGet, "/todos", "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 2, result.Counts.Shown)
+
+		status, result, _ = do(t, ts, http.MethodGet, "/todos?all=true", "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 3, result.Counts.Shown)
+
+		status, result, _ = do(t, ts, http.MethodPost, "/todos/c1/reopen", "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 0, result.Counts.Done)
+
+		status, _, _ = do(t, ts, http.MethodPost, "/todos/1/complete", "")
+		require.Equal(t, http.StatusOK, status)
+		status, result, _ = do(t, ts, http.MethodPost, "/clean", "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "Cleaned 1 todo", result.Message)
+	})
+
+	t.Run("responds with the same json as --format json", func(t *testing.T) {
+		ts, dbPath := newTestServer(t)
+
+		status, _, _ := do(t, ts, http.MethodPost, "/todos", `{"text": "Write docs"}`)
+		require.Equal(t, http.StatusOK, status)
+		_, _, body := do(t, ts, http.MethodGet, "/todos", "")
+
+		expected, err := too.ExecuteUnifiedCommand("list", nil, map[string]interface{}{
+			"collectionPath": dbPath,
+			"done":           false,
+			"all":            false,
+		})
+		require.NoError(t, err)
+		outputEngine, err := output.NewEngine()
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, outputEngine.GetLipbalmEngine().Render(&buf, "json", expected))
+
+		assert.Equal(t, buf.String(), body)
+	})
+
+	t.Run("reports errors as json", func(t *testing.T) {
+		ts, _ := newTestServer(t)
+
+		status, _, body := do(t, ts, http.MethodPost, "/todos/9/complete", "")
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Contains(t, body, `"error"`)
+		assert.Contains(t, body, `"kind": "not_found"`)
+
+		do(t, ts, http.MethodPost, "/todos", `{"text": "Buy milk"}`)
+		do(t, ts, http.MethodPost, "/todos", `{"text": "Buy bread"}`)
+		status, _, body = do(t, ts, http.MethodPost, "/todos/Buy/complete", "")
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Contains(t, body, `"kind": "ambiguous"`)
+		assert.Contains(t, body, `"candidates"`)
+
+		status, _, body = do(t, ts, http.MethodPost, "/todos", "not json")
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, body, "invalid request body")
+
+		status, _, _ = do(t, ts, http.MethodGet, "/search", "")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+	t.Run("refuses requests a web page could forge", func(t *testing.T) {
+		ts, _ := newTestServer(t)
+		do(t, ts, http.MethodPost, "/todos", `{"text": "Buy milk"}`)
+		_, port, err := net.SplitHostPort(ts.Listener.Addr().String())
+		require.NoError(t, err)
+
+		request := func(method, path, contentType string, header map[string]string) *http.Request {
+			req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(""))
+			require.NoError(t, err)
+			if contentType != "" {
+				req.Header.Set("Content-Type", contentType)
+			}
+			for key, value := range header {
+				req.Header.Set(key, value)
+			}
+			return req
+		}
+
+		// A form posted from another site, with or without a content type
+		status, _, _ := send(t, request(http.MethodPost, "/clean", "", nil))
+		assert.Equal(t, http.StatusUnsupportedMediaType, status)
+		status, _, _ = send(t, request(http.MethodPost, "/todos/1/complete", "application/x-www-form-urlencoded", nil))
+		assert.Equal(t, http.StatusUnsupportedMediaType, status)
+		status, _, _ = send(t, request(http.MethodPost, "/todos/1/complete", "text/plain", nil))
+		assert.Equal(t, http.StatusUnsupportedMediaType, status)
+
+		// A foreign origin, even with the right content type
+		status, _, _ = send(t, request(http.MethodPost, "/todos/1/complete", "application/json", map[string]string{"Origin": "http://evil.example"}))
+		assert.Equal(t, http.StatusForbidden, status)
+		status, _, _ = send(t, request(http.MethodGet, "/todos", "", map[string]string{"Origin": "http://evil.example"}))
+		assert.Equal(t, http.StatusForbidden, status)
+
+		// DNS rebinding: the right address under another name
+		rebound := request(http.MethodGet, "/todos", "", nil)
+		rebound.Host = "evil.example:" + port
+		status, _, _ = send(t, rebound)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		// Nothing changed
+		status, result, _ := do(t, ts, http.MethodGet, "/todos", "")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 1, result.Counts.Shown)
+		assert.Equal(t, 0, result.Counts.Done)
+
+		// The loopback names and the server's own origin are fine
+		local := request(http.MethodPost, "/todos/1/complete", "application/json; charset=utf-8", map[string]string{"Origin": "http://localhost:" + port})
+		local.Host = "localhost:" + port
+		status, _, _ = send(t, local)
+		assert.Equal(t, http.StatusOK, status)
+	})
+}
@@ -0,0 +1,108 @@
+package too
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// ShortIDPrefix marks a reference as a short ID, like "@a3f9"
+const ShortIDPrefix = "@"
+
+// MinShortIDLength is the fewest hex digits a short ID has, as in git
+const MinShortIDLength = 4
+
+// compactUUID is uuid as matched by short IDs: lower case, without dashes
+func compactUUID(uuid string) string {
+	return strings.ToLower(strings.ReplaceAll(uuid, "-", ""))
+}
+
+// isShortIDRef reports whether ref is a short ID reference: the prefix and
+// at least MinShortIDLength hex digits. Anything else, like the context
+// "@phone", is left to the other kinds of references.
+func isShortIDRef(ref string) bool {
+	if !strings.HasPrefix(ref, ShortIDPrefix) {
+		return false
+	}
+	prefix := compactUUID(strings.TrimPrefix(ref, ShortIDPrefix))
+	return len(prefix) >= MinShortIDLength && strings.Trim(prefix, "0123456789abcdef") == ""
+}
+
+// ShortIDs returns each todo's short ID, keyed by UID: the shortest prefix
+// of its UUID, at least MinShortIDLength long, that no other todo shares.
+// Unlike position paths, a short ID never changes, though it may grow when
+// a todo with a close UUID is added.
+func ShortIDs(todos []*models.Todo) map[string]string {
+	type entry struct{ uid, compact string }
+	entries := make([]entry, 0, len(todos))
+	for _, todo := range todos {
+		entries = append(entries, entry{todo.UID, compactUUID(todo.UID)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].compact < entries[j].compact
+	})
+
+	// In sorted order, the closest UUIDs are the neighbors
+	ids := make(map[string]string, len(entries))
+	for i, e := range entries {
+		length := MinShortIDLength
+		if i > 0 {
+			length = max(length, commonPrefixLength(e.compact, entries[i-1].compact)+1)
+		}
+		if i+1 < len(entries) {
+			length = max(length, commonPrefixLength(e.compact, entries[i+1].compact)+1)
+		}
+		ids[e.uid] = e.compact[:min(length, len(e.compact))]
+	}
+	return ids
+}
+
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// assignShortIDs sets the ShortID of the given todos, computed across the
+// whole store
+func (e *NanoEngine) assignShortIDs(lists ...[]*models.Todo) error {
+	all, err := e.adapter.List(true)
+	if err != nil {
+		return err
+	}
+	ids := ShortIDs(all)
+	for _, todos := range lists {
+		for _, todo := range todos {
+			todo.ShortID = ids[todo.UID]
+		}
+	}
+	return nil
+}
+
+// resolveShortID finds the todo whose UUID starts with the short ID ref, see
+// isShortIDRef
+func (e *NanoEngine) resolveShortID(ref string) (string, error) {
+	prefix := compactUUID(strings.TrimPrefix(ref, ShortIDPrefix))
+	todos, err := e.adapter.List(true)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []*models.Todo
+	for _, todo := range todos {
+		if strings.HasPrefix(compactUUID(todo.UID), prefix) {
+			matches = append(matches, todo)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", NewNotFoundError(ref)
+	case 1:
+		return matches[0].UID, nil
+	default:
+		return "", NewAmbiguousError(ref, matches)
+	}
+}
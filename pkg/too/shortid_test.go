@@ -0,0 +1,103 @@
+package too
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortIDs(t *testing.T) {
+	todos := []*models.Todo{
+		{UID: "a3f91111-0000-0000-0000-000000000000"},
+		{UID: "a3f92222-0000-0000-0000-000000000000"},
+		{UID: "a3e00000-0000-0000-0000-000000000000"},
+		{UID: "B7000000-0000-0000-0000-000000000000"},
+		{UID: "a3f92223-0000-0000-0000-000000000000"},
+	}
+
+	ids := ShortIDs(todos)
+	assert.Equal(t, map[string]string{
+		"a3f91111-0000-0000-0000-000000000000": "a3f91",
+		"a3f92222-0000-0000-0000-000000000000": "a3f92222",
+		"a3e00000-0000-0000-0000-000000000000": "a3e0",
+		"B7000000-0000-0000-0000-000000000000": "b700",
+		"a3f92223-0000-0000-0000-000000000000": "a3f92223",
+	}, ids)
+}
+
+func TestResolveShortID(t *testing.T) {
+	adapter, dbPath := testutil.CreateStoreWithSpecs(t,
+		testutil.TodoSpec{Text: "Buy milk"},
+		testutil.TodoSpec{Text: "Walk dog"},
+		testutil.TodoSpec{Text: "Call mom @phone"},
+		testutil.TodoSpec{Text: "Meet Ana @cafe"},
+	)
+	defer adapter.Close()
+
+	engine, err := NewNanoEngine(dbPath)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	todos, err := engine.List(true)
+	require.NoError(t, err)
+	ids := ShortIDs(todos)
+
+	t.Run("resolves short and longer prefixes", func(t *testing.T) {
+		for _, todo := range todos {
+			uid, err := engine.ResolveReference("@" + ids[todo.UID])
+			require.NoError(t, err)
+			assert.Equal(t, todo.UID, uid)
+
+			uid, err = engine.ResolveReference("@" + strings.ToUpper(todo.UID[:13]))
+			require.NoError(t, err)
+			assert.Equal(t, todo.UID, uid)
+		}
+	})
+
+	t.Run("other words after @ are free text", func(t *testing.T) {
+		uid, err := engine.ResolveReference("@phone")
+		require.NoError(t, err)
+		assert.Equal(t, todos[2].UID, uid)
+
+		// Hex words match a todo's text when they match no UUID
+		for _, todo := range todos {
+			if strings.HasPrefix(compactUUID(todo.UID), "cafe") {
+				t.Skip("a UUID starts with cafe")
+			}
+		}
+		uid, err = engine.ResolveReference("@cafe")
+		require.NoError(t, err)
+		assert.Equal(t, todos[3].UID, uid)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := engine.ResolveReference("@a3f")
+		assert.Equal(t, ErrNotFound, KindOf(err))
+
+		unknown := "0000"
+		for _, todo := range todos {
+			if strings.HasPrefix(compactUUID(todo.UID), unknown) {
+				unknown = "ffff"
+			}
+		}
+		_, err = engine.ResolveReference("@" + unknown)
+		assert.Equal(t, ErrNotFound, KindOf(err))
+		assert.Contains(t, err.Error(), "no todo with short ID")
+	})
+
+	t.Run("commands set them and take them", func(t *testing.T) {
+		walk := todos[1]
+		result, err := ExecuteUnifiedCommandWithEngine(engine, "complete", []string{"@" + ids[walk.UID]}, map[string]interface{}{})
+		require.NoError(t, err)
+		require.Len(t, result.AffectedTodos, 1)
+		assert.Equal(t, "Walk dog", result.AffectedTodos[0].Text)
+		assert.Equal(t, ids[walk.UID], result.AffectedTodos[0].ShortID)
+		for _, todo := range result.AllTodos {
+			assert.Equal(t, ids[todo.UID], todo.ShortID)
+		}
+	})
+}
@@ -0,0 +1,380 @@
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Document fields the merge treats specially
+const (
+	uuidKey       = "uuid"
+	titleKey      = "title"
+	bodyKey       = "body"
+	updatedAtKey  = "updated_at"
+	dimensionsKey = "dimensions"
+	statusKey     = "status"
+	parentKey     = "parent_uuid"
+	doneStatus    = "completed"
+)
+
+// document is a todo as stored, its fields left encoded
+type document map[string]json.RawMessage
+
+// MergeStores merges two versions of a store file, ours and theirs, made
+// from base, the way git merges text files: todos are matched by UUID and
+// merged field by field, each side's changes applied. When both sides
+// changed a field:
+//
+//   - a done status wins over a pending one
+//   - the later update time is kept
+//   - for the text and notes, ours is kept and their version is added as a
+//     copy of the todo, right after it, so that nothing is lost
+//   - for anything else, ours is kept
+//
+// A todo changed on one side and deleted on the other is kept, with its
+// parents. The result is in the shared layout (see WriteSharedLayout),
+// along with the number of todos kept in both versions. An empty base
+// stands for a file both sides added.
+func MergeStores(base, ours, theirs []byte) ([]byte, int, error) {
+	baseFile, baseDocs, err := parseStore(base)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read the base version: %w", err)
+	}
+	ourFile, ourDocs, err := parseStore(ours)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read our version: %w", err)
+	}
+	theirFile, theirDocs, err := parseStore(theirs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read their version: %w", err)
+	}
+
+	baseByUUID, ourByUUID, theirByUUID := byUUID(baseDocs), byUUID(ourDocs), byUUID(theirDocs)
+
+	// Ours come first, then the todos only they have, in their order
+	var uuids []string
+	seen := make(map[string]bool)
+	for _, docs := range [][]document{ourDocs, theirDocs, baseDocs} {
+		for _, doc := range docs {
+			if uuid := uuidOf(doc); !seen[uuid] {
+				seen[uuid] = true
+				uuids = append(uuids, uuid)
+			}
+		}
+	}
+
+	var merged []document
+	kept := make(map[string]bool)
+	conflicts := 0
+	for _, uuid := range uuids {
+		baseDoc, ourDoc, theirDoc := baseByUUID[uuid], ourByUUID[uuid], theirByUUID[uuid]
+		switch {
+		case ourDoc == nil && theirDoc == nil:
+			// Deleted on both sides
+			continue
+		case ourDoc == nil:
+			// Deleted by us: gone unless they changed it
+			if baseDoc != nil && sameValue(baseDoc.encode(), theirDoc.encode()) {
+				continue
+			}
+			merged = append(merged, theirDoc)
+		case theirDoc == nil:
+			if baseDoc != nil && sameValue(baseDoc.encode(), ourDoc.encode()) {
+				continue
+			}
+			merged = append(merged, ourDoc)
+		default:
+			doc, copied, err := mergeDocument(baseDoc, ourDoc, theirDoc)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to merge todo %s: %w", uuid, err)
+			}
+			merged = append(merged, doc)
+			if copied != nil {
+				merged = append(merged, copied)
+				conflicts++
+			}
+		}
+		kept[uuid] = true
+	}
+
+	// Bring back the parents deleted on one side of todos the other kept
+	for restored := true; restored; {
+		restored = false
+		for _, doc := range merged {
+			parent := parentOf(doc)
+			if parent == "" || kept[parent] {
+				continue
+			}
+			for _, docs := range []map[string]document{ourByUUID, theirByUUID, baseByUUID} {
+				if found, ok := docs[parent]; ok {
+					merged = append(merged, found)
+					kept[parent] = true
+					restored = true
+					break
+				}
+			}
+		}
+	}
+
+	// The rest of the file merges as whole values
+	file := make(map[string]json.RawMessage)
+	for _, key := range unionKeys(baseFile, ourFile, theirFile) {
+		if key == documentsKey {
+			continue
+		}
+		value, ok := mergeValue(baseFile[key], ourFile[key], theirFile[key])
+		if !ok && changedBoth(baseFile[key], ourFile[key], theirFile[key]) {
+			value, ok = ourFile[key], ourFile[key] != nil
+		}
+		if ok {
+			file[key] = value
+		}
+	}
+	documents, err := json.Marshal(merged)
+	if err != nil {
+		return nil, 0, err
+	}
+	file[documentsKey] = documents
+
+	content, err := json.Marshal(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	laidOut, err := sharedLayout(content)
+	if err != nil {
+		return nil, 0, err
+	}
+	return laidOut, conflicts, nil
+}
+
+// mergeDocument merges both sides' changes to a todo, see MergeStores. When
+// both changed its text or notes, it also returns a copy of the todo with
+// theirs.
+func mergeDocument(base, ours, theirs document) (document, document, error) {
+	merged := make(document)
+	textConflict := false
+	for _, key := range unionKeys(base, ours, theirs) {
+		baseValue, ourValue, theirValue := base[key], ours[key], theirs[key]
+		if value, ok := mergeValue(baseValue, ourValue, theirValue); ok || !changedBoth(baseValue, ourValue, theirValue) {
+			if ok {
+				merged[key] = value
+			}
+			continue
+		}
+
+		switch key {
+		case dimensionsKey:
+			dimensions, err := mergeDimensions(baseValue, ourValue, theirValue)
+			if err != nil {
+				return nil, nil, err
+			}
+			merged[key] = dimensions
+		case updatedAtKey:
+			merged[key] = later(ourValue, theirValue)
+		case titleKey, bodyKey:
+			textConflict = true
+			merged[key] = ourValue
+		default:
+			merged[key] = ourValue
+		}
+		if merged[key] == nil {
+			delete(merged, key)
+		}
+	}
+	if !textConflict {
+		return merged, nil, nil
+	}
+
+	copied := make(document, len(merged))
+	for key, value := range merged {
+		copied[key] = value
+	}
+	for _, key := range []string{titleKey, bodyKey} {
+		if value, ok := theirs[key]; ok {
+			copied[key] = value
+		} else {
+			delete(copied, key)
+		}
+	}
+	uuid, err := newUUID()
+	if err != nil {
+		return nil, nil, err
+	}
+	copied[uuidKey], _ = json.Marshal(uuid)
+	return merged, copied, nil
+}
+
+// mergeDimensions merges a todo's dimensions one by one. A done status wins
+// over a pending one, ours wins other conflicts.
+func mergeDimensions(base, ours, theirs json.RawMessage) (json.RawMessage, error) {
+	var baseDims, ourDims, theirDims map[string]json.RawMessage
+	for _, side := range []struct {
+		raw  json.RawMessage
+		dims *map[string]json.RawMessage
+	}{{base, &baseDims}, {ours, &ourDims}, {theirs, &theirDims}} {
+		if side.raw == nil {
+			continue
+		}
+		if err := json.Unmarshal(side.raw, side.dims); err != nil {
+			return nil, err
+		}
+	}
+
+	merged := make(map[string]json.RawMessage)
+	for _, key := range unionKeys(baseDims, ourDims, theirDims) {
+		baseValue, ourValue, theirValue := baseDims[key], ourDims[key], theirDims[key]
+		value, ok := mergeValue(baseValue, ourValue, theirValue)
+		if !ok && changedBoth(baseValue, ourValue, theirValue) {
+			value, ok = ourValue, ourValue != nil
+			if key == statusKey && sameValue(theirValue, mustEncode(doneStatus)) {
+				value, ok = theirValue, true
+			}
+		}
+		if ok {
+			merged[key] = value
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// mergeValue applies the changes of each side to base. It reports false
+// when the value is gone, or when both sides changed it differently, see
+// changedBoth.
+func mergeValue(base, ours, theirs json.RawMessage) (json.RawMessage, bool) {
+	switch {
+	case sameValue(ours, theirs), sameValue(base, theirs):
+		return ours, ours != nil
+	case sameValue(base, ours):
+		return theirs, theirs != nil
+	}
+	return nil, false
+}
+
+// changedBoth reports whether both sides changed a value, each its own way
+func changedBoth(base, ours, theirs json.RawMessage) bool {
+	return !sameValue(ours, theirs) && !sameValue(base, ours) && !sameValue(base, theirs)
+}
+
+// sameValue reports whether two encoded values are equal, however they are
+// laid out. Missing values are equal to each other only.
+func sameValue(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return normalize(a) == normalize(b)
+}
+
+// normalize re-encodes a value with sorted keys and no spacing
+func normalize(raw json.RawMessage) string {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return string(raw)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return string(raw)
+	}
+	return string(encoded)
+}
+
+// later returns the later of two encoded timestamps
+func later(ours, theirs json.RawMessage) json.RawMessage {
+	var ourTime, theirTime string
+	_ = json.Unmarshal(ours, &ourTime)
+	_ = json.Unmarshal(theirs, &theirTime)
+	a, errA := time.Parse(time.RFC3339Nano, ourTime)
+	b, errB := time.Parse(time.RFC3339Nano, theirTime)
+	if errA == nil && errB == nil && b.After(a) {
+		return theirs
+	}
+	return ours
+}
+
+// parseStore decodes a store file into its top level values and its todos.
+// An empty file has neither.
+func parseStore(content []byte) (map[string]json.RawMessage, []document, error) {
+	file := make(map[string]json.RawMessage)
+	if len(bytes.TrimSpace(content)) == 0 {
+		return file, nil, nil
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, nil, err
+	}
+	var docs []document
+	if raw, ok := file[documentsKey]; ok {
+		if err := json.Unmarshal(raw, &docs); err != nil {
+			return nil, nil, err
+		}
+	}
+	for i, doc := range docs {
+		if uuidOf(doc) == "" {
+			return nil, nil, fmt.Errorf("todo %d has no uuid", i+1)
+		}
+	}
+	return file, docs, nil
+}
+
+// encode returns the todo encoded, for comparisons
+func (d document) encode() json.RawMessage {
+	encoded, _ := json.Marshal(map[string]json.RawMessage(d))
+	return encoded
+}
+
+func byUUID(docs []document) map[string]document {
+	found := make(map[string]document, len(docs))
+	for _, doc := range docs {
+		found[uuidOf(doc)] = doc
+	}
+	return found
+}
+
+func uuidOf(doc document) string {
+	var uuid string
+	_ = json.Unmarshal(doc[uuidKey], &uuid)
+	return uuid
+}
+
+func parentOf(doc document) string {
+	var dimensions map[string]interface{}
+	_ = json.Unmarshal(doc[dimensionsKey], &dimensions)
+	parent, _ := dimensions[parentKey].(string)
+	return parent
+}
+
+// unionKeys returns the keys of all the objects, sorted
+func unionKeys[T any](objects ...map[string]T) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, object := range objects {
+		for key := range object {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func mustEncode(value interface{}) json.RawMessage {
+	encoded, _ := json.Marshal(value)
+	return encoded
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
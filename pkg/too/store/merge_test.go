@@ -0,0 +1,145 @@
+package store_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeStores(t *testing.T) {
+	type todo struct {
+		UUID       string            `json:"uuid"`
+		Title      string            `json:"title"`
+		Body       string            `json:"body,omitempty"`
+		CreatedAt  string            `json:"created_at"`
+		UpdatedAt  string            `json:"updated_at,omitempty"`
+		Dimensions map[string]string `json:"dimensions"`
+	}
+
+	newTodo := func(uuid, title, status string) todo {
+		return todo{
+			UUID:       uuid,
+			Title:      title,
+			CreatedAt:  "2025-01-01T00:00:0" + uuid[len(uuid)-1:] + "Z",
+			Dimensions: map[string]string{"status": status},
+		}
+	}
+
+	file := func(t *testing.T, todos ...todo) []byte {
+		t.Helper()
+		content, err := json.Marshal(map[string]interface{}{
+			"documents": todos,
+			"metadata":  map[string]string{"version": "1.0"},
+		})
+		require.NoError(t, err)
+		return content
+	}
+
+	merge := func(t *testing.T, base, ours, theirs []byte) ([]todo, int) {
+		t.Helper()
+		merged, conflicts, err := store.MergeStores(base, ours, theirs)
+		require.NoError(t, err)
+		var result struct {
+			Documents []todo `json:"documents"`
+		}
+		require.NoError(t, json.Unmarshal(merged, &result))
+		return result.Documents, conflicts
+	}
+
+	titles := func(todos []todo) []string {
+		var found []string
+		for _, todo := range todos {
+			found = append(found, todo.Title)
+		}
+		return found
+	}
+
+	milk, report := newTodo("u1", "Buy milk", "pending"), newTodo("u2", "Write report", "pending")
+
+	t.Run("applies both sides' changes to different todos", func(t *testing.T) {
+		base := file(t, milk, report)
+		doneMilk := milk
+		doneMilk.Dimensions = map[string]string{"status": "completed"}
+		renamed := report
+		renamed.Title = "Write the report"
+		added := newTodo("u3", "New thing", "pending")
+
+		merged, conflicts := merge(t, base, file(t, doneMilk, report), file(t, milk, renamed, added))
+		assert.Zero(t, conflicts)
+		assert.Equal(t, []string{"Buy milk", "Write the report", "New thing"}, titles(merged))
+		assert.Equal(t, "completed", merged[0].Dimensions["status"])
+	})
+
+	t.Run("merges one todo field by field", func(t *testing.T) {
+		base := file(t, milk)
+		ours := milk
+		ours.Title = "Buy oat milk"
+		theirs := milk
+		theirs.Body = "from the corner shop"
+		theirs.Dimensions = map[string]string{"status": "pending", "parent_uuid": ""}
+
+		merged, conflicts := merge(t, base, file(t, ours), file(t, theirs))
+		assert.Zero(t, conflicts)
+		require.Len(t, merged, 1)
+		assert.Equal(t, "Buy oat milk", merged[0].Title)
+		assert.Equal(t, "from the corner shop", merged[0].Body)
+		assert.Contains(t, merged[0].Dimensions, "parent_uuid")
+	})
+
+	t.Run("done wins over pending", func(t *testing.T) {
+		ours, theirs := milk, milk
+		theirs.Dimensions = map[string]string{"status": "completed"}
+
+		// Without a base, both sides added the todo
+		merged, _ := merge(t, nil, file(t, ours), file(t, theirs))
+		require.Len(t, merged, 1)
+		assert.Equal(t, "completed", merged[0].Dimensions["status"])
+
+		merged, _ = merge(t, nil, file(t, theirs), file(t, ours))
+		assert.Equal(t, "completed", merged[0].Dimensions["status"])
+	})
+
+	t.Run("keeps both versions of conflicting text", func(t *testing.T) {
+		base := file(t, report)
+		ours, theirs := report, report
+		ours.Title, ours.UpdatedAt = "Write the report", "2025-02-01T00:00:00Z"
+		theirs.Title, theirs.UpdatedAt = "Write report (draft)", "2025-03-01T00:00:00Z"
+
+		merged, conflicts := merge(t, base, file(t, ours), file(t, theirs))
+		assert.Equal(t, 1, conflicts)
+		assert.Equal(t, []string{"Write the report", "Write report (draft)"}, titles(merged))
+		assert.Equal(t, "u2", merged[0].UUID)
+		assert.NotEqual(t, "u2", merged[1].UUID)
+		assert.Len(t, merged[1].UUID, 36)
+		assert.Equal(t, "2025-03-01T00:00:00Z", merged[0].UpdatedAt)
+	})
+
+	t.Run("deletions apply unless the other side changed the todo", func(t *testing.T) {
+		base := file(t, milk, report)
+		renamed := report
+		renamed.Title = "Write the report"
+
+		// Ours deleted milk, which they left alone, and report, which they changed
+		merged, _ := merge(t, base, file(t), file(t, milk, renamed))
+		assert.Equal(t, []string{"Write the report"}, titles(merged))
+	})
+
+	t.Run("brings back the parents of kept todos", func(t *testing.T) {
+		child := newTodo("u3", "Pack bags", "pending")
+		child.Dimensions["parent_uuid"] = "u1"
+		base := file(t, milk, child)
+		changed := child
+		changed.Title = "Pack the bags"
+
+		merged, _ := merge(t, base, file(t), file(t, milk, changed))
+		assert.Equal(t, []string{"Buy milk", "Pack the bags"}, titles(merged))
+	})
+
+	t.Run("rejects what isn't a store", func(t *testing.T) {
+		_, _, err := store.MergeStores(nil, []byte("<<<<<<< HEAD"), file(t))
+		assert.Error(t, err)
+	})
+}
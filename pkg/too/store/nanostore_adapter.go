@@ -8,11 +8,20 @@ import (
 
 	"github.com/arthur-debert/nanostore/nanostore"
 	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/scope"
+	"github.com/gofrs/flock"
 )
 
+// branchField holds a todo's git branch. Branch names are open ended, so it
+// is custom data rather than a configured dimension.
+const branchField = "_data.branch"
+
 // NanoStoreAdapter wraps nanostore to provide too-specific functionality
 type NanoStoreAdapter struct {
 	store nanostore.Store
+	path  string
+	// shared stores are committed to git and kept in the shared layout
+	shared bool
 }
 
 // NewNanoStoreAdapter creates a new adapter instance
@@ -54,12 +63,42 @@ func NewNanoStoreAdapter(dbPath string) (*NanoStoreAdapter, error) {
 		return nil, fmt.Errorf("failed to create nanostore: %w", err)
 	}
 
-	return &NanoStoreAdapter{store: store}, nil
+	return &NanoStoreAdapter{store: store, path: dbPath, shared: scope.IsShared(dir)}, nil
+}
+
+// Path returns the store's file path, with ~ expanded
+func (a *NanoStoreAdapter) Path() string {
+	return a.path
 }
 
-// Close releases resources
+// Close releases resources. Shared stores are then rewritten in the shared
+// layout, see WriteSharedLayout.
 func (n *NanoStoreAdapter) Close() error {
-	return n.store.Close()
+	if err := n.store.Close(); err != nil {
+		return err
+	}
+	return n.write(func() error { return nil })
+}
+
+// write makes a change to the store. Shared stores are rewritten in the
+// shared layout right after each change, with the layout lock held around
+// both so that no other too process writes to the file in between and has
+// its change lost, see LayoutLockPath.
+func (n *NanoStoreAdapter) write(change func() error) error {
+	if !n.shared {
+		return change()
+	}
+
+	lock := flock.New(LayoutLockPath(n.path))
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", n.path, err)
+	}
+	defer lock.Unlock()
+
+	if err := change(); err != nil {
+		return err
+	}
+	return WriteSharedLayout(n.path)
 }
 
 // CompleteByUUID marks a todo as completed by its UUID
@@ -67,7 +106,7 @@ func (n *NanoStoreAdapter) CompleteByUUID(uuid string) error {
 	updates := nanostore.UpdateRequest{
 		Dimensions: map[string]interface{}{"status": "completed"},
 	}
-	return n.store.Update(uuid, updates)
+	return n.write(func() error { return n.store.Update(uuid, updates) })
 }
 
 // ReopenByUUID marks a completed todo as pending by its UUID
@@ -75,7 +114,7 @@ func (n *NanoStoreAdapter) ReopenByUUID(uuid string) error {
 	updates := nanostore.UpdateRequest{
 		Dimensions: map[string]interface{}{"status": "pending"},
 	}
-	return n.store.Update(uuid, updates)
+	return n.write(func() error { return n.store.Update(uuid, updates) })
 }
 
 // UpdateByUUID modifies a todo's text by its UUID
@@ -83,7 +122,24 @@ func (n *NanoStoreAdapter) UpdateByUUID(uuid string, text string) error {
 	updates := nanostore.UpdateRequest{
 		Title: &text,
 	}
-	return n.store.Update(uuid, updates)
+	return n.write(func() error { return n.store.Update(uuid, updates) })
+}
+
+// UpdateNotesByUUID replaces a todo's notes by its UUID
+func (n *NanoStoreAdapter) UpdateNotesByUUID(uuid string, notes string) error {
+	updates := nanostore.UpdateRequest{
+		Body: &notes,
+	}
+	return n.write(func() error { return n.store.Update(uuid, updates) })
+}
+
+// SetBranchByUUID tags a todo with a git branch by its UUID, an empty
+// branch untags it
+func (n *NanoStoreAdapter) SetBranchByUUID(uuid string, branch string) error {
+	updates := nanostore.UpdateRequest{
+		Dimensions: map[string]interface{}{branchField: branch},
+	}
+	return n.write(func() error { return n.store.Update(uuid, updates) })
 }
 
 // MoveByUUID changes a todo's parent by its UUID
@@ -105,7 +161,7 @@ func (n *NanoStoreAdapter) MoveByUUID(uuid string, newParentID *string) error {
 	} else {
 		updates.Dimensions["parent_uuid"] = ""
 	}
-	return n.store.Update(uuid, updates)
+	return n.write(func() error { return n.store.Update(uuid, updates) })
 }
 
 // Add creates a new todo item
@@ -123,7 +179,12 @@ func (n *NanoStoreAdapter) Add(text string, parentID *string) (*models.Todo, err
 	if parentID != nil && *parentID != "" {
 		dimensions["parent_uuid"] = *parentID
 	}
-	uuid, err := n.store.Add(text, dimensions)
+	var uuid string
+	err := n.write(func() error {
+		var err error
+		uuid, err = n.store.Add(text, dimensions)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to add todo: %w", err)
 	}
@@ -142,7 +203,7 @@ func (n *NanoStoreAdapter) Complete(userFacingID string) error {
 	updates := nanostore.UpdateRequest{
 		Dimensions: map[string]interface{}{"status": "completed"},
 	}
-	return n.store.Update(userFacingID, updates)
+	return n.write(func() error { return n.store.Update(userFacingID, updates) })
 }
 
 // Reopen marks a completed todo as pending
@@ -150,7 +211,7 @@ func (n *NanoStoreAdapter) Reopen(userFacingID string) error {
 	updates := nanostore.UpdateRequest{
 		Dimensions: map[string]interface{}{"status": "pending"},
 	}
-	return n.store.Update(userFacingID, updates)
+	return n.write(func() error { return n.store.Update(userFacingID, updates) })
 }
 
 // Update modifies a todo's text
@@ -158,7 +219,7 @@ func (n *NanoStoreAdapter) Update(userFacingID string, text string) error {
 	updates := nanostore.UpdateRequest{
 		Title: &text,
 	}
-	return n.store.Update(userFacingID, updates)
+	return n.write(func() error { return n.store.Update(userFacingID, updates) })
 }
 
 // Move changes a todo's parent
@@ -181,17 +242,23 @@ func (n *NanoStoreAdapter) Move(userFacingID string, newParentID *string) error
 		updates.Dimensions["parent_uuid"] = ""
 	}
 
-	return n.store.Update(userFacingID, updates)
+	return n.write(func() error { return n.store.Update(userFacingID, updates) })
 }
 
 // Delete removes a todo and optionally its children
 func (n *NanoStoreAdapter) Delete(userFacingID string, cascade bool) error {
-	return n.store.Delete(userFacingID, cascade)
+	return n.write(func() error { return n.store.Delete(userFacingID, cascade) })
 }
 
 // DeleteCompleted removes all completed todos
 func (n *NanoStoreAdapter) DeleteCompleted() (int, error) {
-	return n.store.DeleteByDimension(map[string]interface{}{"status": "completed"})
+	var deleted int
+	err := n.write(func() error {
+		var err error
+		deleted, err = n.store.DeleteByDimension(map[string]interface{}{"status": "completed"})
+		return err
+	})
+	return deleted, err
 }
 
 // List returns todos based on options
@@ -353,6 +420,7 @@ func (n *NanoStoreAdapter) documentToTodo(doc nanostore.Document) *models.Todo {
 	todo := &models.Todo{
 		UID:          doc.UUID,
 		Text:         doc.Title,
+		Notes:        doc.Body,
 		PositionPath: doc.SimpleID,
 		ParentID:     "",
 		Statuses: map[string]string{
@@ -361,6 +429,10 @@ func (n *NanoStoreAdapter) documentToTodo(doc nanostore.Document) *models.Todo {
 		Modified: doc.UpdatedAt,
 	}
 
+	if branch, ok := doc.Dimensions[branchField].(string); ok {
+		todo.Branch = branch
+	}
+
 	// Set ParentID if has parent
 	if parentUUID, ok := doc.Dimensions["parent_uuid"].(string); ok && parentUUID != "" {
 		todo.ParentID = parentUUID
@@ -0,0 +1,181 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// documentsKey holds the todos in a store file
+const documentsKey = "documents"
+
+// LayoutLockPath returns the path of the lock file too processes hold while
+// they change a shared store and lay it out, next to the store
+func LayoutLockPath(path string) string {
+	return path + ".layout.lock"
+}
+
+// WriteSharedLayout rewrites the store file at path in the layout used for
+// stores committed to git: one block per todo, in list order, with the
+// UUID first and the other fields sorted. The same todos always give the
+// same file, so diffs show only what changed. The file is left alone when
+// it is missing or already laid out.
+func WriteSharedLayout(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	laidOut, err := sharedLayout(content)
+	if err != nil {
+		return fmt.Errorf("failed to lay out %s: %w", path, err)
+	}
+	if bytes.Equal(content, laidOut) {
+		return nil
+	}
+
+	// Replace the file in one step, so readers never see half of it
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(laidOut); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// sharedLayout returns the store file content in the shared layout, see
+// WriteSharedLayout
+func sharedLayout(content []byte) ([]byte, error) {
+	var file map[string]json.RawMessage
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+	var documents []map[string]json.RawMessage
+	if raw, ok := file[documentsKey]; ok {
+		if err := json.Unmarshal(raw, &documents); err != nil {
+			return nil, err
+		}
+	}
+
+	// Todos are listed in creation order, keeping the file's order for ties
+	created := make([]time.Time, len(documents))
+	for i, document := range documents {
+		var createdAt string
+		_ = json.Unmarshal(document["created_at"], &createdAt)
+		created[i], _ = time.Parse(time.RFC3339Nano, createdAt)
+	}
+	order := make([]int, len(documents))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return created[order[a]].Before(created[order[b]])
+	})
+
+	var out bytes.Buffer
+	out.WriteString("{\n")
+	for i, key := range sortedKeys(file, "") {
+		if i > 0 {
+			out.WriteString(",\n")
+		}
+		out.WriteString("  " + quote(key) + ": ")
+		if key != documentsKey {
+			if err := writeValue(&out, file[key], "  "); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if len(documents) == 0 {
+			out.WriteString("[]")
+			continue
+		}
+		out.WriteString("[\n")
+		for j, index := range order {
+			if j > 0 {
+				out.WriteString(",\n")
+			}
+			if err := writeDocument(&out, documents[index]); err != nil {
+				return nil, err
+			}
+		}
+		out.WriteString("\n  ]")
+	}
+	out.WriteString("\n}\n")
+	return out.Bytes(), nil
+}
+
+// writeDocument writes one todo's block, its UUID first
+func writeDocument(out *bytes.Buffer, document map[string]json.RawMessage) error {
+	out.WriteString("    {\n")
+	for i, key := range sortedKeys(document, "uuid") {
+		if i > 0 {
+			out.WriteString(",\n")
+		}
+		out.WriteString("      " + quote(key) + ": ")
+		if err := writeValue(out, document[key], "      "); err != nil {
+			return err
+		}
+	}
+	out.WriteString("\n    }")
+	return nil
+}
+
+// writeValue writes a JSON value indented at indent, with object keys
+// sorted and numbers kept as they were
+func writeValue(out *bytes.Buffer, raw json.RawMessage, indent string) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(indent, "  ")
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	out.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+	return nil
+}
+
+// sortedKeys returns the keys of object sorted, with first, when present,
+// ahead of the others
+func sortedKeys(object map[string]json.RawMessage, first string) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		if key != first {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if _, ok := object[first]; ok {
+		keys = append([]string{first}, keys...)
+	}
+	return keys
+}
+
+// quote returns key as a JSON string
+func quote(key string) string {
+	encoded, _ := json.Marshal(key)
+	return string(encoded)
+}
@@ -0,0 +1,107 @@
+package store_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/scope"
+	"github.com/arthur-debert/too/pkg/too/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSharedLayout(t *testing.T) {
+	t.Run("writes one block per todo in list order", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".todos.json")
+		content := `{"metadata":{"version":"1.0"},"documents":[` +
+			`{"title":"Second","uuid":"b","created_at":"2025-01-02T00:00:00Z","dimensions":{"status":"pending","parent_uuid":"a"},"order":1.50},` +
+			`{"uuid":"a","title":"First & <more>","created_at":"2025-01-01T00:00:00Z","dimensions":{"status":"completed"}}]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+		require.NoError(t, store.WriteSharedLayout(path))
+		laidOut, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, `{
+  "documents": [
+    {
+      "uuid": "a",
+      "created_at": "2025-01-01T00:00:00Z",
+      "dimensions": {
+        "status": "completed"
+      },
+      "title": "First & <more>"
+    },
+    {
+      "uuid": "b",
+      "created_at": "2025-01-02T00:00:00Z",
+      "dimensions": {
+        "parent_uuid": "a",
+        "status": "pending"
+      },
+      "order": 1.50,
+      "title": "Second"
+    }
+  ],
+  "metadata": {
+    "version": "1.0"
+  }
+}
+`, string(laidOut))
+
+		// Laying out again changes nothing
+		require.NoError(t, store.WriteSharedLayout(path))
+		again, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, laidOut, again)
+	})
+
+	t.Run("keeps an empty store valid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".todos.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"documents":[]}`), 0644))
+
+		require.NoError(t, store.WriteSharedLayout(path))
+		laidOut, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "{\n  \"documents\": []\n}\n", string(laidOut))
+	})
+
+	t.Run("ignores a missing store", func(t *testing.T) {
+		assert.NoError(t, store.WriteSharedLayout(filepath.Join(t.TempDir(), "none.json")))
+	})
+
+	t.Run("shared stores are laid out after each change", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+		_, err := scope.SetShared(root)
+		require.NoError(t, err)
+		path := filepath.Join(root, ".todos.json")
+
+		adapter, err := store.NewNanoStoreAdapter(path)
+		require.NoError(t, err)
+		defer adapter.Close()
+		_, err = adapter.Add("Write docs", nil)
+		require.NoError(t, err)
+
+		// Long sessions keep the file laid out, not only once they end
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "\"title\": \"Write docs\"")
+		require.NoError(t, store.WriteSharedLayout(path))
+		laidOut, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, string(laidOut), string(content))
+		assert.FileExists(t, store.LayoutLockPath(path))
+	})
+
+	t.Run("other stores keep nanostore's layout", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".todos.json")
+		adapter, err := store.NewNanoStoreAdapter(path)
+		require.NoError(t, err)
+		_, err = adapter.Add("Write docs", nil)
+		require.NoError(t, err)
+		require.NoError(t, adapter.Close())
+
+		assert.NoFileExists(t, store.LayoutLockPath(path))
+	})
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrStoreChanged reports a store file changed by someone else while a
+// staged change to it was being made, see Staged
+var ErrStoreChanged = errors.New("the store changed while the change was being made")
+
+// Staged is a copy of a store file that a change spanning many writes is
+// made to. The store only gets the change in one step, once it is complete,
+// so that other processes never see it half done and a failed change never
+// has to be undone over their writes.
+type Staged struct {
+	path     string
+	dir      string
+	original []byte
+	existed  bool
+}
+
+// Stage copies the store file at path, if there is one, for a change to be
+// made to. The copy is in a directory of its own next to the store, where
+// a store of the same name is laid out as the store would be.
+func Stage(path string) (*Staged, error) {
+	original, err := os.ReadFile(path)
+	existed := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".too-staged-*")
+	if err != nil {
+		return nil, err
+	}
+	staged := &Staged{path: path, dir: dir, original: original, existed: existed}
+	if existed {
+		if err := os.WriteFile(staged.Path(), original, 0644); err != nil {
+			_ = staged.Discard()
+			return nil, err
+		}
+	}
+	return staged, nil
+}
+
+// Path returns the path of the copy to make the change to
+func (s *Staged) Path() string {
+	return filepath.Join(s.dir, filepath.Base(s.path))
+}
+
+// Commit replaces the store with the changed copy. It fails with
+// ErrStoreChanged, leaving the store alone, when the store is no longer as
+// it was copied.
+func (s *Staged) Commit() error {
+	current, err := os.ReadFile(s.path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+	if exists != s.existed || !bytes.Equal(current, s.original) {
+		return ErrStoreChanged
+	}
+
+	if _, err := os.Stat(s.Path()); errors.Is(err, os.ErrNotExist) {
+		return s.Discard()
+	}
+	if err := os.Rename(s.Path(), s.path); err != nil {
+		return err
+	}
+	return s.Discard()
+}
+
+// Discard drops the copy. It is safe to call after Commit.
+func (s *Staged) Discard() error {
+	return os.RemoveAll(s.dir)
+}
@@ -0,0 +1,77 @@
+package store_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaged(t *testing.T) {
+	setup := func(t *testing.T) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "todos.json")
+		require.NoError(t, os.WriteFile(path, []byte("before"), 0644))
+		return path
+	}
+
+	entries := func(t *testing.T, path string) []string {
+		t.Helper()
+		found, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		var names []string
+		for _, entry := range found {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	t.Run("commit replaces the store with the copy", func(t *testing.T) {
+		path := setup(t)
+		staged, err := store.Stage(path)
+		require.NoError(t, err)
+		copied, err := os.ReadFile(staged.Path())
+		require.NoError(t, err)
+		assert.Equal(t, "before", string(copied))
+
+		require.NoError(t, os.WriteFile(staged.Path(), []byte("after"), 0644))
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "before", string(content), "the store is untouched until the commit")
+
+		require.NoError(t, staged.Commit())
+		content, err = os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "after", string(content))
+		assert.Equal(t, []string{"todos.json"}, entries(t, path))
+	})
+
+	t.Run("keeps changes made meanwhile by others", func(t *testing.T) {
+		path := setup(t)
+		staged, err := store.Stage(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(staged.Path(), []byte("staged"), 0644))
+		require.NoError(t, os.WriteFile(path, []byte("theirs"), 0644))
+
+		assert.ErrorIs(t, staged.Commit(), store.ErrStoreChanged)
+		require.NoError(t, staged.Discard())
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "theirs", string(content))
+		assert.Equal(t, []string{"todos.json"}, entries(t, path))
+	})
+
+	t.Run("discard leaves the store alone", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "new.json")
+		staged, err := store.Stage(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(staged.Path(), []byte("staged"), 0644))
+
+		require.NoError(t, staged.Discard())
+		assert.NoFileExists(t, path)
+		assert.Empty(t, entries(t, path))
+	})
+}
@@ -0,0 +1,238 @@
+package too
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// Symbolic references name todos by their relation to others:
+//
+//	last          the most recently added or modified todo
+//	<ref>^        the parent of ref, repeatable like git's HEAD^^; ^ alone is last^
+//	parent:<ref>  the parent of ref
+//	first:<ref>   the first child of ref
+//	last:<ref>    the last child of ref
+//	next:<ref>    the sibling after ref
+//	prev:<ref>    the sibling before ref
+//
+// Children and siblings are taken in list order among todos of the same
+// status: the pending ones, as a plain list shows them. first: and last:
+// use the done children when all children are done, and next: and prev:
+// step through done todos when ref is done.
+const (
+	symbolLast   = "last"
+	symbolParent = "^"
+)
+
+// symbolicPrefixes are the relations taking a reference after the colon
+var symbolicPrefixes = []string{"parent:", "first:", "last:", "next:", "prev:"}
+
+// UnwrapSymbolicRef splits a symbolic reference around the reference it is
+// relative to, so that "next:milk^" gives "next:", "milk" and "^". Other
+// references come back whole as inner.
+func UnwrapSymbolicRef(ref string) (prefix, inner, suffix string) {
+	inner = strings.TrimRight(ref, symbolParent)
+	suffix = ref[len(inner):]
+	if inner == "" && suffix != "" {
+		return "", symbolLast, suffix
+	}
+
+	for {
+		found := false
+		for _, p := range symbolicPrefixes {
+			if strings.HasPrefix(inner, p) && len(inner) > len(p) {
+				prefix += p
+				inner = inner[len(p):]
+				found = true
+			}
+		}
+		if !found {
+			return prefix, inner, suffix
+		}
+	}
+}
+
+// isSymbolicRef reports whether ref is a symbolic reference
+func isSymbolicRef(ref string) bool {
+	prefix, inner, suffix := UnwrapSymbolicRef(ref)
+	return prefix != "" || suffix != "" || inner == symbolLast
+}
+
+// resolveSymbol resolves symbolic references. It reports false when ref
+// isn't one. freeText tells whether the reference inside may be free text.
+func (e *NanoEngine) resolveSymbol(ref string, freeText bool) (string, bool, error) {
+	if ref == symbolLast {
+		uuid, err := e.lastModified()
+		return uuid, true, err
+	}
+
+	if strings.HasSuffix(ref, symbolParent) {
+		inner := strings.TrimSuffix(ref, symbolParent)
+		if inner == "" {
+			inner = symbolLast
+		}
+		uuid, err := e.resolveReference(inner, freeText)
+		if err != nil {
+			return "", true, err
+		}
+		uuid, err = e.parentOf(ref, uuid)
+		return uuid, true, err
+	}
+
+	for _, prefix := range symbolicPrefixes {
+		if !strings.HasPrefix(ref, prefix) {
+			continue
+		}
+		inner := strings.TrimPrefix(ref, prefix)
+		if strings.TrimSpace(inner) == "" {
+			return "", true, NewInvalidRefError(ref, fmt.Sprintf("%s needs a reference, like %s1", prefix, prefix))
+		}
+		uuid, err := e.resolveReference(inner, freeText)
+		if err != nil {
+			return "", true, err
+		}
+
+		switch prefix {
+		case "parent:":
+			uuid, err = e.parentOf(ref, uuid)
+		case "first:":
+			uuid, err = e.childOf(ref, uuid, true)
+		case "last:":
+			uuid, err = e.childOf(ref, uuid, false)
+		case "next:":
+			uuid, err = e.siblingOf(ref, uuid, 1)
+		case "prev:":
+			uuid, err = e.siblingOf(ref, uuid, -1)
+		}
+		return uuid, true, err
+	}
+
+	return "", false, nil
+}
+
+// lastModified returns the most recently added or modified todo
+func (e *NanoEngine) lastModified() (string, error) {
+	todos, err := e.adapter.List(true)
+	if err != nil {
+		return "", err
+	}
+
+	var last *models.Todo
+	for _, todo := range todos {
+		if last == nil || !todo.Modified.Before(last.Modified) {
+			last = todo
+		}
+	}
+	if last == nil {
+		return "", noRelatedTodo(symbolLast, "there are no todos")
+	}
+	return last.UID, nil
+}
+
+// parentOf returns the parent of the todo with uuid
+func (e *NanoEngine) parentOf(ref, uuid string) (string, error) {
+	todo, err := e.adapter.GetByUUID(uuid)
+	if err != nil {
+		return "", err
+	}
+	if todo.ParentID == "" {
+		return "", noRelatedTodo(ref, "'%s' is a top level todo", todo.Text)
+	}
+	return todo.ParentID, nil
+}
+
+// childOf returns the first or last child of the todo with uuid
+func (e *NanoEngine) childOf(ref, uuid string, first bool) (string, error) {
+	children, err := e.adapter.GetChildrenOf(uuid)
+	if err != nil {
+		return "", err
+	}
+
+	shown := withStatus(children, models.StatusPending)
+	if len(shown) == 0 {
+		shown = withStatus(children, models.StatusDone)
+	}
+	if len(shown) == 0 {
+		parent, err := e.adapter.GetByUUID(uuid)
+		if err != nil {
+			return "", err
+		}
+		return "", noRelatedTodo(ref, "'%s' has no children", parent.Text)
+	}
+
+	sortByPosition(shown)
+	if first {
+		return shown[0].UID, nil
+	}
+	return shown[len(shown)-1].UID, nil
+}
+
+// siblingOf returns the sibling offset places away from the todo with uuid
+func (e *NanoEngine) siblingOf(ref, uuid string, offset int) (string, error) {
+	todo, err := e.adapter.GetByUUID(uuid)
+	if err != nil {
+		return "", err
+	}
+	siblings, err := e.adapter.GetSiblingsOf(uuid)
+	if err != nil {
+		return "", err
+	}
+
+	shown := append(withStatus(siblings, todo.GetStatus()), todo)
+	sortByPosition(shown)
+	for i, sibling := range shown {
+		if sibling.UID != todo.UID {
+			continue
+		}
+		if i+offset < 0 || i+offset >= len(shown) {
+			break
+		}
+		return shown[i+offset].UID, nil
+	}
+
+	which := "next"
+	if offset < 0 {
+		which = "previous"
+	}
+	return "", noRelatedTodo(ref, "'%s' has no %s sibling", todo.Text, which)
+}
+
+// withStatus returns the todos with the given status
+func withStatus(todos []*models.Todo, status models.TodoStatus) []*models.Todo {
+	var result []*models.Todo
+	for _, todo := range todos {
+		if todo.GetStatus() == status {
+			result = append(result, todo)
+		}
+	}
+	return result
+}
+
+// sortByPosition sorts siblings in list order, by the last segment of their
+// position paths
+func sortByPosition(todos []*models.Todo) {
+	index := func(todo *models.Todo) int {
+		path := todo.PositionPath
+		if i := strings.LastIndex(path, "."); i >= 0 {
+			path = path[i+1:]
+		}
+		n, _ := strconv.Atoi(strings.TrimLeft(path, "cp"))
+		return n
+	}
+	sort.SliceStable(todos, func(i, j int) bool {
+		return index(todos[i]) < index(todos[j])
+	})
+}
+
+// noRelatedTodo reports a symbolic reference with nothing to point at
+func noRelatedTodo(ref, format string, args ...interface{}) error {
+	return &Error{
+		Kind:    ErrNotFound,
+		Message: fmt.Sprintf("nothing at '%s': %s", ref, fmt.Sprintf(format, args...)),
+		Ref:     ref,
+	}
+}
@@ -0,0 +1,97 @@
+package too
+
+import (
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolicReferences(t *testing.T) {
+	adapter, dbPath := testutil.CreateStoreWithSpecs(t,
+		testutil.TodoSpec{Text: "Groceries"},
+		testutil.TodoSpec{Text: "Milk", ParentPos: "1"},
+		testutil.TodoSpec{Text: "Bread", ParentPos: "1"},
+		testutil.TodoSpec{Text: "Eggs", ParentPos: "1"},
+		testutil.TodoSpec{Text: "Jam", ParentPos: "1", Complete: true},
+		testutil.TodoSpec{Text: "Laundry"},
+	)
+	defer adapter.Close()
+
+	engine, err := NewNanoEngine(dbPath)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	text := func(t *testing.T, ref string) string {
+		t.Helper()
+		uuid, err := engine.ResolveReference(ref)
+		require.NoError(t, err, ref)
+		todo, err := engine.GetTodoByUID(uuid)
+		require.NoError(t, err)
+		return todo.Text
+	}
+
+	t.Run("relations", func(t *testing.T) {
+		for ref, expected := range map[string]string{
+			"parent:1.2":      "Groceries",
+			"1.2^":            "Groceries",
+			"first:1":         "Milk",
+			"last:1":          "Eggs",
+			"next:1.1":        "Bread",
+			"prev:1.3":        "Bread",
+			"next:1":          "Laundry",
+			"next:first:1":    "Bread",
+			"last:1^":         "Groceries",
+			"parent:next:1.1": "Groceries",
+			"next:milk":       "Bread",
+		} {
+			assert.Equal(t, expected, text(t, ref), ref)
+		}
+	})
+
+	t.Run("done todos step through done siblings", func(t *testing.T) {
+		jam := text(t, "1.c1")
+		assert.Equal(t, "Jam", jam)
+		_, err := engine.ResolveReference("next:1.c1")
+		assert.Equal(t, ErrNotFound, KindOf(err))
+	})
+
+	t.Run("last is the most recently changed", func(t *testing.T) {
+		assert.Equal(t, "Laundry", text(t, "last"))
+
+		uuid, err := engine.ResolveReference("1.2")
+		require.NoError(t, err)
+		_, err = engine.MutateAttributeByUUID(uuid, models.AttributeText, "Rye bread")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Rye bread", text(t, "last"))
+		assert.Equal(t, "Groceries", text(t, "^"))
+		assert.Equal(t, "Groceries", text(t, "last^"))
+	})
+
+	t.Run("nothing to point at", func(t *testing.T) {
+		for _, ref := range []string{"1^", "parent:2", "first:2", "next:2", "prev:1", "1^^^"} {
+			_, err := engine.ResolveReference(ref)
+			assert.Equal(t, ErrNotFound, KindOf(err), ref)
+		}
+
+		_, err := engine.ResolveReference("next:")
+		assert.Equal(t, ErrInvalidRef, KindOf(err))
+	})
+}
+
+func TestUnwrapSymbolicRef(t *testing.T) {
+	for ref, expected := range map[string][3]string{
+		"milk":           {"", "milk", ""},
+		"next:milk":      {"next:", "milk", ""},
+		"parent:last:2^": {"parent:last:", "2", "^"},
+		"last":           {"", "last", ""},
+		"^^":             {"", "last", "^^"},
+		"next:":          {"", "next:", ""},
+	} {
+		prefix, inner, suffix := UnwrapSymbolicRef(ref)
+		assert.Equal(t, expected, [3]string{prefix, inner, suffix}, ref)
+	}
+}
@@ -62,6 +62,7 @@ type TodoSpec struct {
 	Text      string
 	ParentPos string // Position path of parent (e.g., "1", "1.2")
 	Complete  bool
+	Notes     string
 }
 
 // CreateStoreWithSpecs creates a store with specific todos
@@ -94,6 +95,13 @@ func CreateStoreWithSpecs(t *testing.T, specs ...TodoSpec) (*store.NanoStoreAdap
 		// Store position to UID mapping
 		posToUID[todo.PositionPath] = todo.UID
 		
+		// Set notes if given
+		if spec.Notes != "" {
+			if err := adapter.UpdateNotesByUUID(todo.UID, spec.Notes); err != nil {
+				t.Fatalf("failed to set notes of todo %s: %v", spec.Text, err)
+			}
+		}
+		
 		// Complete if requested
 		if spec.Complete {
 			if err := adapter.Complete(todo.PositionPath); err != nil {
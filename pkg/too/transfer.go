@@ -0,0 +1,275 @@
+package too
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/scope"
+	"github.com/arthur-debert/too/pkg/too/store"
+	"github.com/rs/zerolog/log"
+)
+
+// Scopes a todo can be moved to besides a store path, see ResolveScopeStore
+const (
+	ScopeGlobal  = "global"
+	ScopeProject = "project"
+)
+
+// ResolveScopeStore returns the store for a --to-scope target: the global
+// store, the project store of currentDir, or a store path.
+func ResolveScopeStore(target, currentDir string) (string, error) {
+	switch target {
+	case "":
+		return "", NewValidationError("a scope is needed: %s, %s or a store path", ScopeGlobal, ScopeProject)
+	case ScopeGlobal:
+		return scope.GlobalPath(), nil
+	case ScopeProject:
+		resolved, err := scope.NewResolver(false).Resolve(currentDir)
+		if err != nil {
+			return "", err
+		}
+		if resolved.IsGlobal {
+			return "", NewValidationError("not in a project: %s is only a scope inside a git repository", ScopeProject)
+		}
+		return resolved.Path, nil
+	}
+
+	if strings.HasPrefix(target, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, target[2:]), nil
+		}
+	}
+	return target, nil
+}
+
+// TransferTodo moves the todo ref names, with everything under it, to the
+// top level of the store target names (see ResolveScopeStore). Statuses,
+// notes and branches go along. With keep, the todos are copied instead and the source is
+// left alone. The source only loses the todos once the destination has them:
+// if writing the destination fails it is restored as it was.
+func TransferTodo(ref, target string, keep bool, opts map[string]interface{}) (*ChangeResult, error) {
+	command := "move"
+	if keep {
+		command = "copy"
+	}
+
+	// Qualified references like g:2 name their store
+	storePath, args, opts, err := projectForRefs(scope.DefaultRegistryPath(), "move", []string{ref}, opts)
+	if err != nil {
+		return nil, err
+	}
+	sourcePath, _ := opts["collectionPath"].(string)
+	if storePath != "" {
+		sourcePath = storePath
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		currentDir = "."
+	}
+	destinationPath, err := ResolveScopeStore(target, currentDir)
+	if err != nil {
+		return nil, err
+	}
+	if samePath(sourcePath, destinationPath) {
+		return nil, NewValidationError("the todo is already in %s", target)
+	}
+
+	source, err := NewNanoEngine(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+	source.UseLastView()
+	source.branch, source.branchOnly = source.branchMode(opts)
+
+	uuid, err := source.ResolveReference(args[0])
+	if err != nil {
+		return nil, err
+	}
+	all, err := source.adapter.List(true)
+	if err != nil {
+		return nil, err
+	}
+	subtree := subtreeOf(all, uuid)
+
+	root, err := writeSubtree(destinationPath, subtree)
+	if err != nil {
+		return nil, err
+	}
+
+	if !keep {
+		if err := source.adapter.Delete(uuid, true); err != nil {
+			return nil, fmt.Errorf("copied to %s, but removing the original failed: %w", target, storeError(err))
+		}
+		if source.viewEnabled {
+			if err := source.advanceLastView(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result, err := destinationResult(destinationPath, root)
+	if err != nil {
+		return nil, err
+	}
+	result.Command = command
+	result.Message = transferMessage(command, subtree, target)
+	result.Warnings = source.Warnings()
+	return result, nil
+}
+
+// subtreeOf returns the todo with uuid followed by its descendants, parents
+// before their children, in list order
+func subtreeOf(todos []*models.Todo, uuid string) []*models.Todo {
+	var subtree []*models.Todo
+	var walk func(nodes []*models.HierarchicalTodo, inside bool)
+	walk = func(nodes []*models.HierarchicalTodo, inside bool) {
+		for _, node := range nodes {
+			here := inside || node.UID == uuid
+			if here {
+				subtree = append(subtree, node.Todo)
+			}
+			walk(node.Children, here)
+		}
+	}
+	walk(models.BuildHierarchy(todos), false)
+	return subtree
+}
+
+// writeSubtree adds todos, a todo followed by its descendants, to the top
+// level of the store at path and returns the new todo. The todos are written
+// to a copy of the store, which replaces it once they all are (see
+// store.Stage), so on failure the store is left as it was.
+func writeSubtree(path string, todos []*models.Todo) (root string, err error) {
+	staged, err := store.Stage(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy %s: %w", path, err)
+	}
+	defer func() {
+		if discardErr := staged.Discard(); discardErr != nil {
+			log.Debug().Err(discardErr).Msg("failed to remove the copy of the destination")
+		}
+	}()
+
+	adapter, err := store.NewNanoStoreAdapter(staged.Path())
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, storeError(err))
+	}
+	defer func() {
+		closeErr := adapter.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to close %s: %w", path, closeErr)
+		}
+		if err == nil {
+			if commitErr := staged.Commit(); commitErr != nil {
+				err = fmt.Errorf("failed to write %s: %w", path, commitErr)
+			}
+		}
+	}()
+
+	// Add everything as pending first, then complete the done ones, the
+	// way import does
+	created := make(map[string]string, len(todos))
+	var done []string
+	for _, todo := range todos {
+		var parent *string
+		if newParent, ok := created[todo.ParentID]; ok {
+			parent = &newParent
+		}
+
+		added, err := adapter.Add(todo.Text, parent)
+		if err != nil {
+			return "", fmt.Errorf("failed to write '%s': %w", todo.Text, storeError(err))
+		}
+		created[todo.UID] = added.UID
+
+		if todo.Notes != "" {
+			if err := adapter.UpdateNotesByUUID(added.UID, todo.Notes); err != nil {
+				return "", fmt.Errorf("failed to write the notes of '%s': %w", todo.Text, storeError(err))
+			}
+		}
+		if todo.Branch != "" {
+			if err := adapter.SetBranchByUUID(added.UID, todo.Branch); err != nil {
+				return "", fmt.Errorf("failed to write the branch of '%s': %w", todo.Text, storeError(err))
+			}
+		}
+		if todo.GetStatus() == models.StatusDone {
+			done = append(done, added.UID)
+		}
+	}
+	for _, uid := range done {
+		if err := adapter.CompleteByUUID(uid); err != nil {
+			return "", fmt.Errorf("failed to complete a written todo: %w", storeError(err))
+		}
+	}
+
+	return created[todos[0].UID], nil
+}
+
+// destinationResult lists the store at path with the todo with uid as the
+// affected one
+func destinationResult(path, uid string) (*ChangeResult, error) {
+	engine, err := NewNanoEngine(path)
+	if err != nil {
+		return nil, err
+	}
+	defer engine.Close()
+
+	todos, err := engine.List(false)
+	if err != nil {
+		return nil, err
+	}
+	var affected *models.Todo
+	for _, todo := range todos {
+		if todo.UID == uid {
+			affected = todo
+		}
+	}
+	if affected == nil {
+		if affected, err = engine.GetTodoByUID(uid); err != nil {
+			return nil, err
+		}
+	}
+	if err := engine.assignShortIDs(todos, []*models.Todo{affected}); err != nil {
+		return nil, err
+	}
+
+	total, done := engine.GetStats()
+	return &ChangeResult{
+		AffectedTodos: []*models.Todo{affected},
+		AllTodos:      todos,
+		TotalCount:    total,
+		DoneCount:     done,
+	}, nil
+}
+
+// transferMessage describes a move or copy of subtree to target
+func transferMessage(command string, subtree []*models.Todo, target string) string {
+	verb := "Moved"
+	if command == "copy" {
+		verb = "Copied"
+	}
+	message := fmt.Sprintf("%s '%s'", verb, subtree[0].Text)
+	switch under := len(subtree) - 1; under {
+	case 0:
+	case 1:
+		message += " and 1 todo under it"
+	default:
+		message += fmt.Sprintf(" and %d todos under it", under)
+	}
+	return message + " to " + target
+}
+
+// samePath reports whether two store paths name the same file
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return absA == absB
+}
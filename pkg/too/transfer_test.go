@@ -0,0 +1,150 @@
+package too
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/scope"
+	"github.com/arthur-debert/too/pkg/too/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTodo(t *testing.T) {
+	setup := func(t *testing.T) (map[string]interface{}, string) {
+		t.Helper()
+		t.Setenv("XDG_DATA_HOME", t.TempDir())
+		adapter, dbPath := testutil.CreateStoreWithSpecs(t,
+			testutil.TodoSpec{Text: "Release"},
+			testutil.TodoSpec{Text: "Walk dog"},
+			testutil.TodoSpec{Text: "Write notes", ParentPos: "1", Notes: "see the wiki"},
+			testutil.TodoSpec{Text: "Tag version", ParentPos: "1"},
+		)
+		require.NoError(t, adapter.Close())
+		_, err := ExecuteUnifiedCommand("complete", []string{"1.1"}, map[string]interface{}{"collectionPath": dbPath})
+		require.NoError(t, err)
+		return map[string]interface{}{"collectionPath": dbPath}, filepath.Join(t.TempDir(), "other.json")
+	}
+
+	all := func(t *testing.T, path string) []*models.Todo {
+		t.Helper()
+		result, err := ExecuteUnifiedCommand("list", nil, map[string]interface{}{"collectionPath": path, "all": true})
+		require.NoError(t, err)
+		return result.AllTodos
+	}
+
+	byText := func(todos []*models.Todo) map[string]*models.Todo {
+		found := make(map[string]*models.Todo)
+		for _, todo := range todos {
+			found[todo.Text] = todo
+		}
+		return found
+	}
+
+	t.Run("moves the subtree with statuses and notes", func(t *testing.T) {
+		opts, destination := setup(t)
+
+		result, err := TransferTodo("1", destination, false, opts)
+		require.NoError(t, err)
+		assert.Equal(t, "move", result.Command)
+		assert.Contains(t, result.Message, "Moved 'Release' and 2 todos under it")
+		require.Len(t, result.AffectedTodos, 1)
+		assert.Equal(t, "Release", result.AffectedTodos[0].Text)
+
+		moved := byText(all(t, destination))
+		require.Len(t, moved, 3)
+		assert.Empty(t, moved["Release"].ParentID)
+		assert.Equal(t, moved["Release"].UID, moved["Write notes"].ParentID)
+		assert.Equal(t, moved["Release"].UID, moved["Tag version"].ParentID)
+		assert.Equal(t, models.StatusDone, moved["Write notes"].GetStatus())
+		assert.Equal(t, models.StatusPending, moved["Tag version"].GetStatus())
+		assert.Equal(t, "see the wiki", moved["Write notes"].Notes)
+
+		left := all(t, opts["collectionPath"].(string))
+		require.Len(t, left, 1)
+		assert.Equal(t, "Walk dog", left[0].Text)
+
+		// The todos were written to a copy of the destination, now gone
+		entries, err := os.ReadDir(filepath.Dir(destination))
+		require.NoError(t, err)
+		for _, entry := range entries {
+			assert.NotContains(t, entry.Name(), "staged")
+		}
+	})
+
+	t.Run("copy leaves the source alone", func(t *testing.T) {
+		opts, destination := setup(t)
+
+		result, err := TransferTodo("Walk dog", destination, true, opts)
+		require.NoError(t, err)
+		assert.Equal(t, "copy", result.Command)
+		assert.Equal(t, "Copied 'Walk dog' to "+destination, result.Message)
+
+		assert.Len(t, all(t, destination), 1)
+		assert.Len(t, all(t, opts["collectionPath"].(string)), 4)
+	})
+
+	t.Run("moves to the global store", func(t *testing.T) {
+		opts, _ := setup(t)
+
+		_, err := TransferTodo("2", ScopeGlobal, false, opts)
+		require.NoError(t, err)
+		global := all(t, scope.GlobalPath())
+		require.Len(t, global, 1)
+		assert.Equal(t, "Walk dog", global[0].Text)
+
+		// And back, with a g: reference
+		_, err = TransferTodo("g:1", opts["collectionPath"].(string), false, opts)
+		require.NoError(t, err)
+		assert.Empty(t, all(t, scope.GlobalPath()))
+		assert.Contains(t, byText(all(t, opts["collectionPath"].(string))), "Walk dog")
+	})
+
+	t.Run("the source keeps the todos when the destination fails", func(t *testing.T) {
+		opts, _ := setup(t)
+		destination := t.TempDir() // A directory can't be a store
+
+		_, err := TransferTodo("1", destination, false, opts)
+		require.Error(t, err)
+		assert.Len(t, all(t, opts["collectionPath"].(string)), 4)
+	})
+
+	t.Run("the destination must be another store", func(t *testing.T) {
+		opts, _ := setup(t)
+
+		_, err := TransferTodo("1", opts["collectionPath"].(string), false, opts)
+		assert.Equal(t, ErrValidation, KindOf(err))
+	})
+
+	t.Run("unknown references are not found", func(t *testing.T) {
+		opts, destination := setup(t)
+
+		_, err := TransferTodo("9", destination, false, opts)
+		assert.Equal(t, ErrNotFound, KindOf(err))
+		_, statErr := os.Stat(destination)
+		assert.True(t, os.IsNotExist(statErr), "nothing was written")
+	})
+}
+
+func TestResolveScopeStore(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	path, err := ResolveScopeStore(ScopeGlobal, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, scope.GlobalPath(), path)
+
+	repo := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(repo, ".git"), 0755))
+	path, err = ResolveScopeStore(ScopeProject, repo)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(repo, ".todos.json"), path)
+
+	_, err = ResolveScopeStore(ScopeProject, t.TempDir())
+	assert.Equal(t, ErrValidation, KindOf(err))
+
+	path, err = ResolveScopeStore("/tmp/other.json", repo)
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/other.json", path)
+}
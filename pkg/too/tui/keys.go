@@ -0,0 +1,110 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyType identifies a key press
+type KeyType int
+
+const (
+	// KeyRune is a printable character, see Key.Rune
+	KeyRune KeyType = iota
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyCtrlC
+	KeyCtrlU
+)
+
+// Key is a single key press
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+// Rune returns the key press for a printable character
+func Rune(r rune) Key {
+	return Key{Type: KeyRune, Rune: r}
+}
+
+// Runes returns the key presses for typing a string
+func Runes(s string) []Key {
+	keys := make([]Key, 0, len(s))
+	for _, r := range s {
+		keys = append(keys, Rune(r))
+	}
+	return keys
+}
+
+// escapeSequences maps the terminal escape sequences the UI understands
+var escapeSequences = map[string]KeyType{
+	"\x1b[A": KeyUp,
+	"\x1b[B": KeyDown,
+	"\x1b[C": KeyRight,
+	"\x1b[D": KeyLeft,
+	"\x1bOA": KeyUp,
+	"\x1bOB": KeyDown,
+	"\x1bOC": KeyRight,
+	"\x1bOD": KeyLeft,
+}
+
+// ParseKeys decodes raw terminal input into key presses. Unknown escape
+// sequences are dropped.
+func ParseKeys(input []byte) []Key {
+	var keys []Key
+
+	for len(input) > 0 {
+		if input[0] == 0x1b {
+			if len(input) == 1 {
+				keys = append(keys, Key{Type: KeyEsc})
+				break
+			}
+			if len(input) >= 3 {
+				if keyType, ok := escapeSequences[string(input[:3])]; ok {
+					keys = append(keys, Key{Type: keyType})
+					input = input[3:]
+					continue
+				}
+			}
+			if input[1] == '[' || input[1] == 'O' {
+				// Skip an unknown CSI sequence up to its final byte
+				end := 2
+				for end < len(input) && (input[end] < 0x40 || input[end] > 0x7e) {
+					end++
+				}
+				input = input[min(end+1, len(input)):]
+				continue
+			}
+			keys = append(keys, Key{Type: KeyEsc})
+			input = input[1:]
+			continue
+		}
+
+		switch input[0] {
+		case '\r', '\n':
+			keys = append(keys, Key{Type: KeyEnter})
+		case 0x7f, 0x08:
+			keys = append(keys, Key{Type: KeyBackspace})
+		case '\t':
+			keys = append(keys, Key{Type: KeyTab})
+		case 0x03:
+			keys = append(keys, Key{Type: KeyCtrlC})
+		case 0x15:
+			keys = append(keys, Key{Type: KeyCtrlU})
+		default:
+			r, size := utf8.DecodeRune(input)
+			if r >= 0x20 && r != utf8.RuneError {
+				keys = append(keys, Rune(r))
+			}
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+
+	return keys
+}
@@ -0,0 +1,24 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too/tui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys := tui.ParseKeys([]byte("j\x1b[A\x1b[B\r\x7f\té\x03\x1b[1;5C\x1b"))
+
+	assert.Equal(t, []tui.Key{
+		tui.Rune('j'),
+		{Type: tui.KeyUp},
+		{Type: tui.KeyDown},
+		{Type: tui.KeyEnter},
+		{Type: tui.KeyBackspace},
+		{Type: tui.KeyTab},
+		tui.Rune('é'),
+		{Type: tui.KeyCtrlC},
+		{Type: tui.KeyEsc},
+	}, keys)
+}
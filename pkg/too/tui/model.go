@@ -0,0 +1,458 @@
+// Package tui is a keyboard driven, full-screen view of the todo hierarchy.
+//
+// The Model holds all state and changes only through Update, which takes one
+// key press at a time, and View, which renders the current state. Run wires
+// a Model to a terminal; tests drive the Model directly with keys.
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// mode is what key presses currently go to
+type mode int
+
+const (
+	modeNormal mode = iota
+	modeAddSibling
+	modeAddChild
+	modeEdit
+	modeSearch
+)
+
+// row is one visible line of the tree
+type row struct {
+	todo  *models.HierarchicalTodo
+	depth int
+}
+
+// Model is the state of the UI
+type Model struct {
+	engine *too.NanoEngine
+
+	roots   []*models.HierarchicalTodo
+	parents map[string]*models.HierarchicalTodo
+	rows    []row
+	folded  map[string]bool
+
+	cursor    int
+	cursorUID string
+	offset    int
+
+	mode  mode
+	input []rune
+	query string
+
+	status string
+	quit   bool
+}
+
+// New creates a model showing every todo in the engine's collection
+func New(engine *too.NanoEngine) (*Model, error) {
+	m := &Model{
+		engine: engine,
+		folded: make(map[string]bool),
+	}
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Quit reports whether the user asked to leave
+func (m *Model) Quit() bool {
+	return m.quit
+}
+
+// Selected returns the todo under the cursor, nil when the list is empty
+func (m *Model) Selected() *models.HierarchicalTodo {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return nil
+	}
+	return m.rows[m.cursor].todo
+}
+
+// Update applies one key press
+func (m *Model) Update(key Key) error {
+	if key.Type == KeyCtrlC {
+		m.quit = true
+		return nil
+	}
+
+	if m.mode != modeNormal {
+		return m.updateInput(key)
+	}
+
+	m.status = ""
+	return m.updateNormal(key)
+}
+
+func (m *Model) updateNormal(key Key) error {
+	switch key.Type {
+	case KeyUp:
+		m.moveCursor(-1)
+		return nil
+	case KeyDown:
+		m.moveCursor(1)
+		return nil
+	case KeyTab:
+		m.toggleFold()
+		return nil
+	case KeyLeft:
+		m.foldOrParent()
+		return nil
+	case KeyRight:
+		m.unfold()
+		return nil
+	case KeyRune:
+	default:
+		return nil
+	}
+
+	switch key.Rune {
+	case 'q':
+		m.quit = true
+	case 'j':
+		m.moveCursor(1)
+	case 'k':
+		m.moveCursor(-1)
+	case 'g':
+		m.setCursor(0)
+	case 'G':
+		m.setCursor(len(m.rows) - 1)
+	case 'h':
+		m.foldOrParent()
+	case 'l':
+		m.unfold()
+	case 'z':
+		m.toggleFold()
+	case ' ':
+		return m.toggleCompletion()
+	case 'a':
+		m.startInput(modeAddSibling, "")
+	case 'A':
+		if m.Selected() != nil {
+			m.startInput(modeAddChild, "")
+		}
+	case 'e':
+		if selected := m.Selected(); selected != nil {
+			m.startInput(modeEdit, selected.Text)
+		}
+	case '>':
+		return m.indent()
+	case '<':
+		return m.outdent()
+	case '/':
+		m.startInput(modeSearch, "")
+	case 'n':
+		m.findNext(1)
+	case 'N':
+		m.findNext(-1)
+	}
+	return nil
+}
+
+func (m *Model) startInput(mode mode, initial string) {
+	m.mode = mode
+	m.input = []rune(initial)
+}
+
+func (m *Model) updateInput(key Key) error {
+	switch key.Type {
+	case KeyEsc:
+		m.mode = modeNormal
+		m.input = nil
+		return nil
+	case KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+		return nil
+	case KeyCtrlU:
+		m.input = nil
+		return nil
+	case KeyRune:
+		m.input = append(m.input, key.Rune)
+		return nil
+	case KeyEnter:
+	default:
+		return nil
+	}
+
+	text := strings.TrimSpace(string(m.input))
+	mode := m.mode
+	m.mode = modeNormal
+	m.input = nil
+
+	switch mode {
+	case modeAddSibling:
+		if text == "" {
+			return nil
+		}
+		parent := ""
+		if selected := m.Selected(); selected != nil {
+			parent = selected.ParentID
+		}
+		return m.add(text, parent)
+	case modeAddChild:
+		if text == "" {
+			return nil
+		}
+		selected := m.Selected()
+		m.folded[selected.UID] = false
+		return m.add(text, selected.UID)
+	case modeEdit:
+		if text == "" {
+			m.status = "Text can't be empty"
+			return nil
+		}
+		return m.mutate(m.Selected().UID, models.AttributeText, text)
+	case modeSearch:
+		m.query = text
+		if text != "" {
+			m.findNext(0)
+		}
+	}
+	return nil
+}
+
+// moveCursor moves the cursor by delta rows, stopping at the ends
+func (m *Model) moveCursor(delta int) {
+	m.setCursor(m.cursor + delta)
+}
+
+func (m *Model) setCursor(index int) {
+	if len(m.rows) == 0 {
+		m.cursor = 0
+		m.cursorUID = ""
+		return
+	}
+	m.cursor = max(0, min(index, len(m.rows)-1))
+	m.cursorUID = m.rows[m.cursor].todo.UID
+}
+
+func (m *Model) toggleFold() {
+	selected := m.Selected()
+	if selected == nil || len(selected.Children) == 0 {
+		return
+	}
+	m.folded[selected.UID] = !m.folded[selected.UID]
+	m.rebuildRows()
+}
+
+// foldOrParent folds an open subtree, or moves to the parent otherwise
+func (m *Model) foldOrParent() {
+	selected := m.Selected()
+	if selected == nil {
+		return
+	}
+	if len(selected.Children) > 0 && !m.folded[selected.UID] {
+		m.folded[selected.UID] = true
+		m.rebuildRows()
+		return
+	}
+	if parent, ok := m.parents[selected.UID]; ok {
+		m.cursorUID = parent.UID
+		m.rebuildRows()
+	}
+}
+
+func (m *Model) unfold() {
+	selected := m.Selected()
+	if selected == nil || !m.folded[selected.UID] {
+		return
+	}
+	m.folded[selected.UID] = false
+	m.rebuildRows()
+}
+
+func (m *Model) toggleCompletion() error {
+	selected := m.Selected()
+	if selected == nil {
+		return nil
+	}
+	status := string(models.StatusDone)
+	if selected.GetStatus() == models.StatusDone {
+		status = string(models.StatusPending)
+	}
+	return m.mutate(selected.UID, models.AttributeCompletion, status)
+}
+
+// indent makes the selected todo the last child of its previous sibling
+func (m *Model) indent() error {
+	selected := m.Selected()
+	if selected == nil {
+		return nil
+	}
+
+	siblings := m.siblings(selected)
+	for i, sibling := range siblings {
+		if sibling.UID == selected.UID {
+			if i == 0 {
+				m.status = "Nothing to indent under"
+				return nil
+			}
+			previous := siblings[i-1]
+			m.folded[previous.UID] = false
+			return m.mutate(selected.UID, models.AttributeParent, previous.UID)
+		}
+	}
+	return nil
+}
+
+// outdent makes the selected todo a sibling of its parent
+func (m *Model) outdent() error {
+	selected := m.Selected()
+	if selected == nil {
+		return nil
+	}
+
+	parent, ok := m.parents[selected.UID]
+	if !ok {
+		m.status = "Already at the top level"
+		return nil
+	}
+	return m.mutate(selected.UID, models.AttributeParent, parent.ParentID)
+}
+
+func (m *Model) siblings(todo *models.HierarchicalTodo) []*models.HierarchicalTodo {
+	if parent, ok := m.parents[todo.UID]; ok {
+		return parent.Children
+	}
+	return m.roots
+}
+
+// findNext moves to the next todo matching the search query in the given
+// direction, 0 meaning the current one counts
+func (m *Model) findNext(direction int) {
+	if m.query == "" {
+		return
+	}
+
+	// Search every todo, folded ones included
+	var all []*models.HierarchicalTodo
+	var walk func(nodes []*models.HierarchicalTodo)
+	walk = func(nodes []*models.HierarchicalTodo) {
+		for _, node := range nodes {
+			all = append(all, node)
+			walk(node.Children)
+		}
+	}
+	walk(m.roots)
+
+	start := 0
+	for i, todo := range all {
+		if todo.UID == m.cursorUID {
+			start = i
+			break
+		}
+	}
+
+	query := strings.ToLower(m.query)
+	step := direction
+	if step == 0 {
+		step = 1
+	}
+	for n := 0; n < len(all); n++ {
+		offset := n * step
+		if direction != 0 {
+			offset += step
+		}
+		i := ((start+offset)%len(all) + len(all)) % len(all)
+		if strings.Contains(strings.ToLower(all[i].Text), query) {
+			m.reveal(all[i].UID)
+			return
+		}
+	}
+	m.status = fmt.Sprintf("No match for '%s'", m.query)
+}
+
+// reveal unfolds the ancestors of a todo and moves the cursor to it
+func (m *Model) reveal(uid string) {
+	for parent, ok := m.parents[uid]; ok; parent, ok = m.parents[parent.UID] {
+		m.folded[parent.UID] = false
+	}
+	m.cursorUID = uid
+	m.rebuildRows()
+}
+
+// add creates a todo and selects it
+func (m *Model) add(text, parentUID string) error {
+	todo, err := m.engine.Add(text, &parentUID)
+	if err != nil {
+		m.status = err.Error()
+		return err
+	}
+	m.cursorUID = todo.UID
+	return m.save()
+}
+
+// mutate changes one attribute and keeps the todo selected
+func (m *Model) mutate(uid string, attr models.AttributeType, value interface{}) error {
+	if _, err := m.engine.MutateAttributeByUUID(uid, attr, value); err != nil {
+		m.status = err.Error()
+		return err
+	}
+	m.cursorUID = uid
+	return m.save()
+}
+
+func (m *Model) save() error {
+	if err := m.engine.Save(); err != nil {
+		m.status = err.Error()
+		return err
+	}
+	return m.reload()
+}
+
+// reload reads the todos from the engine and rebuilds the visible rows
+func (m *Model) reload() error {
+	todos, err := m.engine.GetTodos(nil)
+	if err != nil {
+		return fmt.Errorf("failed to load todos: %w", err)
+	}
+
+	m.roots = models.BuildHierarchy(todos)
+	m.parents = make(map[string]*models.HierarchicalTodo)
+	var index func(nodes []*models.HierarchicalTodo)
+	index = func(nodes []*models.HierarchicalTodo) {
+		for _, node := range nodes {
+			for _, child := range node.Children {
+				m.parents[child.UID] = node
+			}
+			index(node.Children)
+		}
+	}
+	index(m.roots)
+
+	m.rebuildRows()
+	return nil
+}
+
+// rebuildRows flattens the unfolded part of the tree and puts the cursor
+// back on the selected todo
+func (m *Model) rebuildRows() {
+	m.rows = m.rows[:0]
+	var walk func(nodes []*models.HierarchicalTodo, depth int)
+	walk = func(nodes []*models.HierarchicalTodo, depth int) {
+		for _, node := range nodes {
+			m.rows = append(m.rows, row{todo: node, depth: depth})
+			if !m.folded[node.UID] {
+				walk(node.Children, depth+1)
+			}
+		}
+	}
+	walk(m.roots, 0)
+
+	for i, r := range m.rows {
+		if r.todo.UID == m.cursorUID {
+			m.setCursor(i)
+			return
+		}
+	}
+	m.setCursor(m.cursor)
+}
@@ -0,0 +1,167 @@
+package tui_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/arthur-debert/too/pkg/too"
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestModel(t *testing.T, texts ...string) (*tui.Model, *too.NanoEngine) {
+	t.Helper()
+
+	engine, err := too.NewNanoEngine(filepath.Join(t.TempDir(), "test.json"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = engine.Close() })
+
+	root := ""
+	for _, text := range texts {
+		_, err := engine.Add(text, &root)
+		require.NoError(t, err)
+	}
+
+	m, err := tui.New(engine)
+	require.NoError(t, err)
+	return m, engine
+}
+
+func press(t *testing.T, m *tui.Model, keys ...tui.Key) {
+	t.Helper()
+	for _, key := range keys {
+		require.NoError(t, m.Update(key))
+	}
+}
+
+func typeLine(t *testing.T, m *tui.Model, command rune, text string) {
+	t.Helper()
+	press(t, m, tui.Rune(command))
+	press(t, m, tui.Runes(text)...)
+	press(t, m, tui.Key{Type: tui.KeyEnter})
+}
+
+func todoByText(t *testing.T, engine *too.NanoEngine, text string) *models.Todo {
+	t.Helper()
+	todos, err := engine.GetTodos(nil)
+	require.NoError(t, err)
+	for _, todo := range todos {
+		if todo.Text == text {
+			return todo
+		}
+	}
+	t.Fatalf("no todo with text %q", text)
+	return nil
+}
+
+func TestModel(t *testing.T) {
+	t.Run("navigates with j and k", func(t *testing.T) {
+		m, _ := newTestModel(t, "First", "Second", "Third")
+
+		assert.Equal(t, "First", m.Selected().Text)
+		press(t, m, tui.Rune('j'), tui.Rune('j'), tui.Rune('j'))
+		assert.Equal(t, "Third", m.Selected().Text)
+		press(t, m, tui.Rune('k'), tui.Key{Type: tui.KeyUp})
+		assert.Equal(t, "First", m.Selected().Text)
+	})
+
+	t.Run("adds siblings and children", func(t *testing.T) {
+		m, engine := newTestModel(t, "Groceries")
+
+		typeLine(t, m, 'A', "Milk")
+		assert.Equal(t, "Milk", m.Selected().Text)
+		typeLine(t, m, 'a', "Eggs")
+
+		groceries := todoByText(t, engine, "Groceries")
+		assert.Equal(t, groceries.UID, todoByText(t, engine, "Milk").ParentID)
+		assert.Equal(t, groceries.UID, todoByText(t, engine, "Eggs").ParentID)
+	})
+
+	t.Run("toggles completion with space", func(t *testing.T) {
+		m, engine := newTestModel(t, "Task")
+
+		press(t, m, tui.Rune(' '))
+		assert.Equal(t, models.StatusDone, todoByText(t, engine, "Task").GetStatus())
+		assert.Equal(t, "Task", m.Selected().Text)
+
+		press(t, m, tui.Rune(' '))
+		assert.Equal(t, models.StatusPending, todoByText(t, engine, "Task").GetStatus())
+	})
+
+	t.Run("edits inline", func(t *testing.T) {
+		m, engine := newTestModel(t, "Draft")
+
+		press(t, m, tui.Rune('e'))
+		assert.Contains(t, m.View(80, 10), "Draft█")
+		press(t, m, tui.Key{Type: tui.KeyCtrlU})
+		press(t, m, tui.Runes("Final")...)
+		press(t, m, tui.Key{Type: tui.KeyEnter})
+
+		todos, err := engine.GetTodos(nil)
+		require.NoError(t, err)
+		require.Len(t, todos, 1)
+		assert.Equal(t, "Final", todos[0].Text)
+	})
+
+	t.Run("escape cancels input", func(t *testing.T) {
+		m, engine := newTestModel(t, "Keep")
+
+		press(t, m, tui.Rune('e'))
+		press(t, m, tui.Runes(" changed")...)
+		press(t, m, tui.Key{Type: tui.KeyEsc})
+
+		assert.Equal(t, "Keep", todoByText(t, engine, "Keep").Text)
+	})
+
+	t.Run("indents and outdents", func(t *testing.T) {
+		m, engine := newTestModel(t, "Parent", "Child")
+
+		press(t, m, tui.Rune('j'), tui.Rune('>'))
+		parent := todoByText(t, engine, "Parent")
+		assert.Equal(t, parent.UID, todoByText(t, engine, "Child").ParentID)
+		assert.Equal(t, "Child", m.Selected().Text)
+
+		press(t, m, tui.Rune('<'))
+		assert.Empty(t, todoByText(t, engine, "Child").ParentID)
+	})
+
+	t.Run("folds subtrees", func(t *testing.T) {
+		m, _ := newTestModel(t, "Parent", "Other")
+		typeLine(t, m, 'A', "Hidden child")
+
+		press(t, m, tui.Rune('k'), tui.Key{Type: tui.KeyTab})
+		view := m.View(80, 10)
+		assert.NotContains(t, view, "Hidden child")
+		assert.Contains(t, view, "▸")
+
+		press(t, m, tui.Rune('j'))
+		assert.Equal(t, "Other", m.Selected().Text)
+
+		press(t, m, tui.Rune('k'), tui.Rune('l'))
+		assert.Contains(t, m.View(80, 10), "Hidden child")
+	})
+
+	t.Run("search reveals folded matches", func(t *testing.T) {
+		m, _ := newTestModel(t, "Parent", "Other")
+		typeLine(t, m, 'A', "Needle")
+		press(t, m, tui.Rune('k'), tui.Rune('h'))
+		assert.NotContains(t, m.View(80, 10), "Needle")
+
+		press(t, m, tui.Rune('G'))
+		typeLine(t, m, '/', "needle")
+		assert.Equal(t, "Needle", m.Selected().Text)
+		assert.Contains(t, m.View(80, 10), "Needle")
+
+		typeLine(t, m, '/', "missing")
+		assert.Contains(t, m.View(80, 10), "No match for 'missing'")
+	})
+
+	t.Run("quits on q", func(t *testing.T) {
+		m, _ := newTestModel(t)
+		assert.Contains(t, m.View(80, 10), "press a to add")
+		press(t, m, tui.Rune('q'))
+		assert.True(t, m.Quit())
+	})
+}
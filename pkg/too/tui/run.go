@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	exitAltScreen  = "\x1b[?25h\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+// Run shows the model full screen on the terminal until the user quits
+func Run(m *Model, in, out *os.File) error {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("too ui needs an interactive terminal")
+	}
+
+	// Adaptive colors query the terminal background, do it before raw mode
+	// so the answer doesn't end up in the key input
+	lipgloss.HasDarkBackground()
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	fmt.Fprint(out, enterAltScreen)
+	defer fmt.Fprint(out, exitAltScreen)
+
+	buf := make([]byte, 256)
+	for {
+		draw(m, out)
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, key := range ParseKeys(buf[:n]) {
+			// Failures are shown in the status line, keep going
+			_ = m.Update(key)
+			if m.Quit() {
+				return nil
+			}
+		}
+	}
+}
+
+func draw(m *Model, out *os.File) {
+	width, height, err := term.GetSize(int(out.Fd()))
+	if err != nil || width == 0 || height == 0 {
+		width, height = 80, 24
+	}
+
+	// Raw mode needs explicit carriage returns
+	view := strings.ReplaceAll(m.View(width, height), "\n", "\r\n")
+	fmt.Fprint(out, clearScreen+view)
+}
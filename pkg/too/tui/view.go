@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/output"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles for the UI, built from the same palette as the regular output
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	cursorStyle   = lipgloss.NewStyle().Reverse(true)
+	doneStyle     = lipgloss.NewStyle().Foreground(output.MUTED_TEXT)
+	positionStyle = lipgloss.NewStyle().Foreground(output.SUBDUED_TEXT)
+	helpStyle     = lipgloss.NewStyle().Foreground(output.SUBDUED_TEXT)
+	statusStyle   = lipgloss.NewStyle().Foreground(output.WARNING_COLOR)
+	matchStyle    = lipgloss.NewStyle().Foreground(output.ACCENT_COLOR).Bold(true)
+)
+
+// statusSymbol renders the effective status with the symbols of the regular
+// output
+func statusSymbol(todo *models.HierarchicalTodo) string {
+	symbol := output.GetStatusSymbol(todo.EffectiveStatus)
+	switch todo.EffectiveStatus {
+	case "done":
+		return output.StatusDone.Render(symbol)
+	case "mixed":
+		return lipgloss.NewStyle().Foreground(output.WARNING_COLOR).Render(symbol)
+	default:
+		return symbol
+	}
+}
+
+const helpLine = "j/k move  space done  a/A add  e edit  >/< indent  / search  tab fold  q quit"
+
+// View renders the model for a terminal of the given size
+func (m *Model) View(width, height int) string {
+	listHeight := max(1, height-3)
+
+	// Keep the cursor in the visible window
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+
+	var lines []string
+
+	total, done := 0, 0
+	var count func(nodes []*models.HierarchicalTodo)
+	count = func(nodes []*models.HierarchicalTodo) {
+		for _, node := range nodes {
+			total++
+			if node.GetStatus() == models.StatusDone {
+				done++
+			}
+			count(node.Children)
+		}
+	}
+	count(m.roots)
+	noun := "todos"
+	if total == 1 {
+		noun = "todo"
+	}
+	lines = append(lines, headerStyle.Render(fmt.Sprintf("too · %d %s, %d done", total, noun, done)))
+
+	if len(m.rows) == 0 {
+		lines = append(lines, helpStyle.Render("No todos yet, press a to add one"))
+	}
+
+	end := min(len(m.rows), m.offset+listHeight)
+	for i := m.offset; i < end; i++ {
+		lines = append(lines, m.renderRow(i, width))
+	}
+	for len(lines) < listHeight+1 {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, "", m.footer())
+	return strings.Join(lines, "\n")
+}
+
+func (m *Model) renderRow(index, width int) string {
+	r := m.rows[index]
+	todo := r.todo
+	indent := strings.Repeat("  ", r.depth)
+
+	fold := " "
+	if len(todo.Children) > 0 {
+		fold = "▾"
+		if m.folded[todo.UID] {
+			fold = "▸"
+		}
+	}
+
+	text := firstLine(todo.Text)
+	if index == m.cursor && m.mode == modeEdit {
+		text = string(m.input) + "█"
+	}
+
+	prefix := fmt.Sprintf("%s%s %s %s ", indent, fold, statusSymbol(todo), positionStyle.Render(todo.PositionPath+"."))
+	if index == m.cursor {
+		return prefix + cursorStyle.Render(truncate(text, width-lipgloss.Width(prefix)))
+	}
+
+	text = truncate(text, width-lipgloss.Width(prefix))
+	if todo.GetStatus() == models.StatusDone {
+		return prefix + doneStyle.Render(text)
+	}
+	return prefix + highlightMatch(text, m.query)
+}
+
+func (m *Model) footer() string {
+	switch m.mode {
+	case modeAddSibling:
+		return "Add: " + string(m.input) + "█"
+	case modeAddChild:
+		return "Add child: " + string(m.input) + "█"
+	case modeEdit:
+		return helpStyle.Render("enter save  esc cancel")
+	case modeSearch:
+		return "/" + string(m.input) + "█"
+	}
+	if m.status != "" {
+		return statusStyle.Render(m.status)
+	}
+	return helpStyle.Render(helpLine)
+}
+
+// highlightMatch marks the first case-insensitive occurrence of query. The
+// match is found in text itself, as lowercasing may change its byte length.
+func highlightMatch(text, query string) string {
+	if query == "" {
+		return text
+	}
+	match := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query)).FindStringIndex(text)
+	if match == nil {
+		return text
+	}
+	return text[:match[0]] + matchStyle.Render(text[match[0]:match[1]]) + text[match[1]:]
+}
+
+func firstLine(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		return text[:i] + " …"
+	}
+	return text
+}
+
+// truncate shortens text to fit width cells
+func truncate(text string, width int) string {
+	if width <= 1 || lipgloss.Width(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
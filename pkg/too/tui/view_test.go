@@ -0,0 +1,16 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlightMatch(t *testing.T) {
+	assert.Equal(t, "Buy "+matchStyle.Render("MILK")+" now", highlightMatch("Buy MILK now", "milk"))
+	assert.Equal(t, "Buy milk", highlightMatch("Buy milk", "bread"))
+	assert.Equal(t, "a.b", highlightMatch("a.b", "a*"))
+
+	// İ takes one more byte once lowercased
+	assert.Equal(t, "İİİ "+matchStyle.Render("Istanbul"), highlightMatch("İİİ Istanbul", "istanbul"))
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/scope"
 	"github.com/rs/zerolog/log"
 )
 
@@ -70,7 +71,7 @@ var UnifiedCommands = map[string]*UnifiedCommand{
 		RequiresText: true,
 		ValidateFunc: func(args []string, opts map[string]interface{}) error {
 			if len(args) < 2 {
-				return fmt.Errorf("edit requires position and new text")
+				return NewValidationError("edit requires position and new text")
 			}
 			return nil
 		},
@@ -88,7 +89,7 @@ var UnifiedCommands = map[string]*UnifiedCommand{
 		RequiresRef: true,
 		ValidateFunc: func(args []string, opts map[string]interface{}) error {
 			if len(args) < 2 {
-				return fmt.Errorf("move requires source and destination")
+				return NewValidationError("move requires source and destination")
 			}
 			return nil
 		},
@@ -106,7 +107,7 @@ var UnifiedCommands = map[string]*UnifiedCommand{
 		RequiresText: true,
 		ValidateFunc: func(args []string, opts map[string]interface{}) error {
 			if len(args) < 1 || args[0] == "" {
-				return fmt.Errorf("add requires todo text")
+				return NewValidationError("add requires todo text")
 			}
 			return nil
 		},
@@ -156,7 +157,7 @@ var UnifiedCommands = map[string]*UnifiedCommand{
 		Description: "Search for todos",
 		ValidateFunc: func(args []string, opts map[string]interface{}) error {
 			if len(args) < 1 {
-				return fmt.Errorf("search requires a query")
+				return NewValidationError("search requires a query")
 			}
 			return nil
 		},
@@ -179,36 +180,22 @@ var UnifiedCommands = map[string]*UnifiedCommand{
 
 // ExecuteUnifiedCommand executes a command using the unified engine
 func ExecuteUnifiedCommand(cmdName string, args []string, opts map[string]interface{}) (*ChangeResult, error) {
-	// Find command
-	cmd, ok := UnifiedCommands[cmdName]
-	if !ok {
-		// Check aliases
-		for name, c := range UnifiedCommands {
-			for _, alias := range c.Aliases {
-				if alias == cmdName {
-					cmd = c
-					cmdName = name
-					break
-				}
-			}
-			if cmd != nil {
-				break
-			}
-		}
-		if cmd == nil {
-			return nil, fmt.Errorf("unknown command: %s", cmdName)
-		}
+	cmd, cmdName, err := prepareUnifiedCommand(cmdName, args, opts)
+	if err != nil {
+		return nil, err
 	}
 	
-	// Validate args
-	if cmd.ValidateFunc != nil {
-		if err := cmd.ValidateFunc(args, opts); err != nil {
-			return nil, err
-		}
+	// References like "api:3" point to another project's store
+	collectionPath, _ := opts["collectionPath"].(string)
+	projectPath, args, opts, err := projectForRefs(scope.DefaultRegistryPath(), cmdName, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	if projectPath != "" {
+		collectionPath = projectPath
 	}
 	
 	// Create engine - use NanoEngine instead
-	collectionPath, _ := opts["collectionPath"].(string)
 	engine, err := NewNanoEngine(collectionPath)
 	if err != nil {
 		return nil, err
@@ -219,24 +206,97 @@ func ExecuteUnifiedCommand(cmdName string, args []string, opts map[string]interf
 		}
 	}()
 	
+	// One-off commands follow on from what the last listing displayed
+	engine.UseLastView()
+	
+	return executeUnifiedCommand(engine, cmd, cmdName, args, opts)
+}
+
+// ExecuteUnifiedCommandWithEngine executes a command against an engine the
+// caller keeps open, so long running sessions don't reopen the store for
+// every command. The collectionPath option is ignored.
+func ExecuteUnifiedCommandWithEngine(engine *NanoEngine, cmdName string, args []string, opts map[string]interface{}) (*ChangeResult, error) {
+	cmd, cmdName, err := prepareUnifiedCommand(cmdName, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	
+	return executeUnifiedCommand(engine, cmd, cmdName, args, opts)
+}
+
+// LookupUnifiedCommand finds a command by name or alias, returning it with
+// its canonical name
+func LookupUnifiedCommand(name string) (*UnifiedCommand, string, bool) {
+	if cmd, ok := UnifiedCommands[name]; ok {
+		return cmd, name, true
+	}
+	
+	// Check aliases
+	for cmdName, cmd := range UnifiedCommands {
+		for _, alias := range cmd.Aliases {
+			if alias == name {
+				return cmd, cmdName, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// prepareUnifiedCommand finds a command by name or alias and validates its
+// arguments, returning the command and its canonical name
+func prepareUnifiedCommand(cmdName string, args []string, opts map[string]interface{}) (*UnifiedCommand, string, error) {
+	cmd, name, ok := LookupUnifiedCommand(cmdName)
+	if !ok {
+		return nil, "", NewValidationError("unknown command: %s", cmdName)
+	}
+	cmdName = name
+	
+	// Validate args
+	if cmd.ValidateFunc != nil {
+		if err := cmd.ValidateFunc(args, opts); err != nil {
+			return nil, "", err
+		}
+	}
+	
+	return cmd, cmdName, nil
+}
+
+// executeUnifiedCommand runs a prepared command against an open engine
+func executeUnifiedCommand(engine *NanoEngine, cmd *UnifiedCommand, cmdName string, args []string, opts map[string]interface{}) (*ChangeResult, error) {
+	var err error
+	
 	// Execute command
 	var affectedUIDs []string
 	var affectedTodos []*models.Todo
 	var todos []*models.Todo
+	var matches []SearchMatch
+	
+	// References and positions leave out the todos of other branches
+	engine.branch, engine.branchOnly = engine.branchMode(opts)
 	
 	switch cmdName {
 	case "add":
 		// Special case: create new todo
 		text := args[0]
 		parentRef := ""
-		if p, ok := opts["parent"].(string); ok {
-			parentRef = p
+		if p, ok := opts["parent"].(string); ok && p != "" {
+			// Resolve the parent up front so a bad one fails with a typed error
+			parentRef, err = engine.resolveTarget(p)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve parent ID '%s': %w", p, err)
+			}
 		}
 		
 		todo, err := engine.Add(text, &parentRef)
 		if err != nil {
 			return nil, err
 		}
+		if branch := engine.branch; engine.branchOnly && branch != "" {
+			if err := engine.adapter.SetBranchByUUID(todo.UID, branch); err != nil {
+				return nil, fmt.Errorf("failed to tag the todo with branch '%s': %w", branch, err)
+			}
+			todo.Branch = branch
+		}
 		affectedUIDs = []string{todo.UID}
 		affectedTodos = []*models.Todo{todo}
 		
@@ -255,22 +315,20 @@ func ExecuteUnifiedCommand(cmdName string, args []string, opts map[string]interf
 		}
 		
 	case "search":
-		// Special case: use native search
-		query := strings.Join(args, " ")
-		showAll := false
-		if all, ok := opts["all"].(bool); ok {
-			showAll = all
-		} else if done, ok := opts["done"].(bool); ok && done {
-			showAll = true
+		// Special case: matches are shown with their ancestors
+		searchOpts, err := searchOptions(strings.Join(args, " "), opts)
+		if err != nil {
+			return nil, err
 		}
 		
-		searchResults, err := engine.Search(query, showAll)
+		searchResults, searchMatches, err := engine.SearchTodos(searchOpts)
 		if err != nil {
 			return nil, err
 		}
 		
 		// For search, the results are the todos to display
 		todos = searchResults
+		matches = searchMatches
 		// No affected todos for search
 		affectedTodos = nil
 		
@@ -324,6 +382,14 @@ func ExecuteUnifiedCommand(cmdName string, args []string, opts map[string]interf
 					}
 				}
 				
+				// Resolve the destination of a move like a parent
+				if dest, ok := value.(string); ok && cmd.Attribute == models.AttributeParent && dest != "" {
+					value, err = engine.resolveTarget(dest)
+					if err != nil {
+						return nil, fmt.Errorf("failed to resolve destination '%s': %w", dest, err)
+					}
+				}
+				
 				uid, err := engine.MutateAttribute(ref, cmd.Attribute, value)
 				if err != nil {
 					return nil, err
@@ -370,6 +436,45 @@ func ExecuteUnifiedCommand(cmdName string, args []string, opts map[string]interf
 		}
 	}
 	
+	// Todos of other branches stay out of sight, and out of the numbering
+	if engine.branchOnly {
+		todos = onBranch(todos, engine.branch)
+		if err := engine.numberOnBranch(todos, affectedTodos); err != nil {
+			return nil, err
+		}
+		if matches != nil {
+			shown := make(map[string]bool, len(todos))
+			for _, todo := range todos {
+				shown[todo.UID] = true
+			}
+			var onBranch []SearchMatch
+			for _, match := range matches {
+				if shown[match.UID] {
+					onBranch = append(onBranch, match)
+				}
+			}
+			matches = onBranch
+		}
+	}
+	
+	// Keep the last view in step: listings replace it, changes made here
+	// don't invalidate it
+	if engine.viewEnabled {
+		var viewErr error
+		if cmdName == "list" || cmdName == "search" {
+			viewErr = engine.recordLastView(cmdName, todos)
+		} else if len(affectedUIDs) > 0 || len(affectedTodos) > 0 {
+			viewErr = engine.advanceLastView()
+		}
+		if viewErr != nil {
+			log.Debug().Err(viewErr).Msg("failed to save the last view")
+		}
+	}
+	
+	if err := engine.assignShortIDs(todos, affectedTodos); err != nil {
+		return nil, err
+	}
+	
 	// Get stats
 	totalCount, doneCount := engine.GetStats()
 	
@@ -378,20 +483,50 @@ func ExecuteUnifiedCommand(cmdName string, args []string, opts map[string]interf
 	if cmd.GetMessageFunc != nil {
 		// For list/search commands, use the filtered todos count
 		messageCount := len(affectedUIDs)
-		if cmdName == "search" || cmdName == "list" {
+		if cmdName == "list" {
 			messageCount = len(todos)
+		} else if cmdName == "search" {
+			messageCount = len(matches)
 		}
 		message = cmd.GetMessageFunc(messageCount, affectedTodos)
 	}
 	
-	return NewChangeResult(
+	result := NewChangeResult(
 		cmdName,
 		message,
 		affectedTodos,
 		todos,
 		totalCount,
 		doneCount,
-	), nil
+	)
+	result.Matches = matches
+	result.Warnings = engine.Warnings()
+	return result, nil
+}
+
+// searchOptions reads the search options out of command options
+func searchOptions(query string, opts map[string]interface{}) (SearchOptions, error) {
+	searchOpts := SearchOptions{Query: query, Mode: SearchSubstring}
+	
+	regex, _ := opts["regex"].(bool)
+	fuzzy, _ := opts["fuzzy"].(bool)
+	switch {
+	case regex && fuzzy:
+		return searchOpts, NewValidationError("search can't be both regex and fuzzy")
+	case regex:
+		searchOpts.Mode = SearchRegex
+	case fuzzy:
+		searchOpts.Mode = SearchFuzzy
+	}
+	
+	searchOpts.In, _ = opts["in"].(string)
+	searchOpts.CaseSensitive, _ = opts["caseSensitive"].(bool)
+	if all, ok := opts["all"].(bool); ok {
+		searchOpts.ShowAll = all
+	} else if done, ok := opts["done"].(bool); ok && done {
+		searchOpts.ShowAll = true
+	}
+	return searchOpts, nil
 }
 
 // formatMessage formats a standard action message
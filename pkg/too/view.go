@@ -0,0 +1,173 @@
+package too
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+)
+
+// LastViewTTL is how long a listing keeps deciding what its IDs refer to.
+// After that, references resolve against the current positions again.
+const LastViewTTL = 15 * time.Minute
+
+// lastView is the snapshot of the IDs the last list or search displayed. It
+// lets "too complete 3" hit the todo shown as 3 even after an earlier command
+// renumbered the list.
+type lastView struct {
+	Command     string            `json:"command"`
+	Created     time.Time         `json:"created"`
+	Fingerprint string            `json:"fingerprint"` // Positions in the store when last known to match
+	IDs         map[string]string `json:"ids"`         // Displayed position path -> UUID
+}
+
+// lastViewPath is where the last view of the store at storePath is kept
+func lastViewPath(storePath string) string {
+	return storePath + ".view"
+}
+
+// UseLastView makes the engine resolve position paths against the IDs the
+// last list or search displayed, and record a new view for every list or
+// search it runs. The snapshot is dropped once it is older than LastViewTTL,
+// or when the store changed in a way too didn't track, in which case
+// resolving one of its IDs adds a warning.
+func (e *NanoEngine) UseLastView() {
+	e.viewEnabled = true
+
+	path := lastViewPath(e.adapter.Path())
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var view lastView
+	if err := json.Unmarshal(data, &view); err != nil || time.Since(view.Created) > LastViewTTL {
+		e.dropLastView()
+		return
+	}
+
+	fingerprint, err := e.positionsFingerprint()
+	if err != nil {
+		return
+	}
+	e.view = &view
+	if fingerprint != view.Fingerprint {
+		e.viewChanged = true
+		e.dropLastView()
+	}
+}
+
+// Warnings returns the warnings raised since the last call and clears them
+func (e *NanoEngine) Warnings() []string {
+	warnings := e.warnings
+	e.warnings = nil
+	return warnings
+}
+
+// resolveFromView looks ref up in the last view. It reports false when the
+// view doesn't decide ref, which then resolves against current positions.
+func (e *NanoEngine) resolveFromView(ref string) (string, bool, error) {
+	if e.view == nil {
+		return "", false, nil
+	}
+	uuid, ok := e.view.IDs[ref]
+	if !ok {
+		return "", false, nil
+	}
+
+	if e.viewChanged {
+		if !e.viewWarned {
+			e.viewWarned = true
+			e.warnings = append(e.warnings, fmt.Sprintf(
+				"todos changed since the last %s, resolved '%s' by its current position", e.view.Command, ref))
+		}
+		return "", false, nil
+	}
+
+	if _, err := e.adapter.GetByUUID(uuid); err != nil {
+		return "", true, &Error{
+			Kind:    ErrNotFound,
+			Message: fmt.Sprintf("todo '%s' from the last %s no longer exists", ref, e.view.Command),
+			Ref:     ref,
+		}
+	}
+
+	e.logger.Debug().Str("ref", ref).Str("uuid", uuid).Msg("resolved reference from the last view")
+	return uuid, true, nil
+}
+
+// recordLastView saves the IDs of todos as the last view
+func (e *NanoEngine) recordLastView(command string, todos []*models.Todo) error {
+	fingerprint, err := e.positionsFingerprint()
+	if err != nil {
+		return err
+	}
+
+	view := &lastView{
+		Command:     command,
+		Created:     time.Now(),
+		Fingerprint: fingerprint,
+		IDs:         make(map[string]string, len(todos)),
+	}
+	for _, todo := range todos {
+		view.IDs[todo.PositionPath] = todo.UID
+	}
+
+	e.view = view
+	e.viewChanged = false
+	return e.saveLastView()
+}
+
+// advanceLastView records that the store's changes since the view are the
+// engine's own, so that the view's IDs keep referring to what was displayed
+func (e *NanoEngine) advanceLastView() error {
+	if e.view == nil || e.viewChanged {
+		return nil
+	}
+	fingerprint, err := e.positionsFingerprint()
+	if err != nil {
+		return err
+	}
+	e.view.Fingerprint = fingerprint
+	return e.saveLastView()
+}
+
+func (e *NanoEngine) saveLastView() error {
+	data, err := json.Marshal(e.view)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(lastViewPath(e.adapter.Path()), data, 0644)
+}
+
+func (e *NanoEngine) dropLastView() {
+	if err := os.Remove(lastViewPath(e.adapter.Path())); err != nil && !os.IsNotExist(err) {
+		e.logger.Debug().Err(err).Msg("failed to remove the last view")
+	}
+}
+
+// positionsFingerprint hashes the position of every todo, so that any change
+// to what IDs refer to changes it
+func (e *NanoEngine) positionsFingerprint() (string, error) {
+	todos, err := e.adapter.List(true)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		lines = append(lines, todo.UID+" "+todo.PositionPath)
+	}
+	sort.Strings(lines)
+
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
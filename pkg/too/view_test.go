@@ -0,0 +1,124 @@
+package too
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/arthur-debert/too/pkg/too/models"
+	"github.com/arthur-debert/too/pkg/too/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastView(t *testing.T) {
+	setup := func(t *testing.T) map[string]interface{} {
+		adapter, dbPath := testutil.CreateStoreWithSpecs(t,
+			testutil.TodoSpec{Text: "Buy milk"},
+			testutil.TodoSpec{Text: "Walk dog"},
+			testutil.TodoSpec{Text: "Pay rent"},
+		)
+		require.NoError(t, adapter.Close())
+		return map[string]interface{}{"collectionPath": dbPath}
+	}
+
+	run := func(t *testing.T, opts map[string]interface{}, cmd string, args ...string) *ChangeResult {
+		t.Helper()
+		result, err := ExecuteUnifiedCommand(cmd, args, opts)
+		require.NoError(t, err)
+		return result
+	}
+
+	status := func(t *testing.T, opts map[string]interface{}) map[string]models.TodoStatus {
+		t.Helper()
+		statuses := make(map[string]models.TodoStatus)
+		all := map[string]interface{}{"collectionPath": opts["collectionPath"], "all": true}
+		for _, todo := range run(t, all, "list").AllTodos {
+			statuses[todo.Text] = todo.GetStatus()
+		}
+		return statuses
+	}
+
+	t.Run("ids keep referring to the listed todos", func(t *testing.T) {
+		opts := setup(t)
+		run(t, opts, "list")
+		run(t, opts, "complete", "1")
+
+		// "Pay rent" is now at position 2, but was listed as 3
+		result := run(t, opts, "complete", "3")
+		assert.Empty(t, result.Warnings)
+		require.Len(t, result.AffectedTodos, 1)
+		assert.Equal(t, "Pay rent", result.AffectedTodos[0].Text)
+		assert.Equal(t, models.StatusPending, status(t, opts)["Walk dog"])
+	})
+
+	t.Run("without a listing ids are positions", func(t *testing.T) {
+		opts := setup(t)
+		run(t, opts, "complete", "1")
+
+		result := run(t, opts, "complete", "2")
+		assert.Equal(t, "Pay rent", result.AffectedTodos[0].Text)
+	})
+
+	t.Run("expired listing", func(t *testing.T) {
+		opts := setup(t)
+		run(t, opts, "list")
+		run(t, opts, "complete", "1")
+
+		path := lastViewPath(opts["collectionPath"].(string))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var view lastView
+		require.NoError(t, json.Unmarshal(data, &view))
+		view.Created = time.Now().Add(-LastViewTTL - time.Minute)
+		data, err = json.Marshal(view)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0644))
+
+		result := run(t, opts, "complete", "1")
+		assert.Equal(t, "Walk dog", result.AffectedTodos[0].Text)
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("changes made elsewhere resolve live with a warning", func(t *testing.T) {
+		opts := setup(t)
+		run(t, opts, "list")
+
+		// A session with its own engine doesn't track the listing
+		engine, err := NewNanoEngine(opts["collectionPath"].(string))
+		require.NoError(t, err)
+		_, err = ExecuteUnifiedCommandWithEngine(engine, "complete", []string{"1"}, opts)
+		require.NoError(t, err)
+		require.NoError(t, engine.Close())
+
+		result := run(t, opts, "complete", "1")
+		assert.Equal(t, "Walk dog", result.AffectedTodos[0].Text)
+		require.Len(t, result.Warnings, 1)
+		assert.Contains(t, result.Warnings[0], "changed since the last list")
+
+		// The listing is gone, so no more warnings
+		result = run(t, opts, "complete", "1")
+		assert.Equal(t, "Pay rent", result.AffectedTodos[0].Text)
+		assert.Empty(t, result.Warnings)
+	})
+
+	t.Run("listed todos that were removed", func(t *testing.T) {
+		opts := setup(t)
+		run(t, opts, "list")
+		run(t, opts, "complete", "1")
+		run(t, opts, "clean")
+
+		_, err := ExecuteUnifiedCommand("complete", []string{"1"}, opts)
+		assert.Equal(t, ErrNotFound, KindOf(err))
+		assert.Contains(t, err.Error(), "from the last list no longer exists")
+	})
+
+	t.Run("ids the listing didn't show resolve live", func(t *testing.T) {
+		opts := setup(t)
+		run(t, opts, "search", "rent")
+
+		result := run(t, opts, "complete", "1")
+		assert.Equal(t, "Buy milk", result.AffectedTodos[0].Text)
+	})
+}